@@ -0,0 +1,179 @@
+package annotations
+
+import (
+	"entrance1/log"
+	"entrance1/persist"
+	"entrance1/tracker"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+type (
+	// Set is every door annotation made for a seed, keyed by door
+	Set struct {
+		Seed  string
+		Doors map[string]tracker.DoorAnnotation
+	}
+	// Request is one change to a seed's annotations
+	Request struct {
+		// Seed defaults to the seed being tracked
+		Seed   string `json:"seed"`
+		Action string `json:"action"`
+		Door   string `json:"door"`
+		// Target is the other door for ActionLink
+		Target string `json:"target"`
+		// Note is the text for ActionNote, empty clears it
+		Note string `json:"note"`
+	}
+)
+
+const (
+	ActionLink    = "link"
+	ActionDeadEnd = "deadend"
+	ActionNote    = "note"
+	ActionUnmark  = "unmark"
+)
+
+const Dir = "doors"
+
+// ErrInvalid is returned for requests that can't be applied, wrapped with the reason
+var ErrInvalid = errors.New("invalid annotation")
+
+var (
+	mu sync.Mutex
+	// cache holds every seed read so far, since the served state is merged with it on every request
+	cache = map[string]Set{}
+)
+
+// load returns the annotations for seed, reading them from disk the first time. callers hold mu
+func load(seed string) (Set, error) {
+	if set, ok := cache[seed]; ok {
+		return set, nil
+	}
+	set := Set{Seed: seed, Doors: map[string]tracker.DoorAnnotation{}}
+	if err := persist.Load(persist.SeedFile(Dir, seed), &set); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Set{}, err
+	}
+	if set.Doors == nil {
+		set.Doors = map[string]tracker.DoorAnnotation{}
+	}
+	cache[seed] = set
+	return set, nil
+}
+
+// Get returns the annotations for seed, which are empty for a seed that was never annotated
+func Get(seed string) (Set, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	set, err := load(seed)
+	if err != nil {
+		return Set{}, err
+	}
+	doors := make(map[string]tracker.DoorAnnotation, len(set.Doors))
+	for door, annotation := range set.Doors {
+		doors[door] = annotation
+	}
+	set.Doors = doors
+	return set, nil
+}
+
+// unlink clears the link on both sides of door
+func unlink(doors map[string]tracker.DoorAnnotation, door string) {
+	annotation := doors[door]
+	if annotation.Link == "" {
+		return
+	}
+	other := doors[annotation.Link]
+	if other.Link == door {
+		other.Link = ""
+		put(doors, annotation.Link, other)
+	}
+	annotation.Link = ""
+	put(doors, door, annotation)
+}
+
+// put stores an annotation, dropping it once there is nothing left on it
+func put(doors map[string]tracker.DoorAnnotation, door string, annotation tracker.DoorAnnotation) {
+	if annotation == (tracker.DoorAnnotation{}) {
+		delete(doors, door)
+		return
+	}
+	doors[door] = annotation
+}
+
+// Apply makes one change to a seed's annotations and saves them. doors are checked against pack
+func Apply(pack *tracker.DataPack, req Request) (Set, error) {
+	if req.Seed == "" {
+		return Set{}, fmt.Errorf("%w: no seed is being tracked", ErrInvalid)
+	}
+	if _, err := pack.GetDoorRegion(req.Door); err != nil {
+		return Set{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	set, err := load(req.Seed)
+	if err != nil {
+		return Set{}, err
+	}
+	doors := make(map[string]tracker.DoorAnnotation, len(set.Doors))
+	for door, annotation := range set.Doors {
+		doors[door] = annotation
+	}
+
+	switch req.Action {
+	case ActionLink:
+		if _, err := pack.GetDoorRegion(req.Target); err != nil {
+			return Set{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		if req.Target == req.Door {
+			return Set{}, fmt.Errorf("%w: a door can't lead to itself", ErrInvalid)
+		}
+		// either door may have been linked somewhere else before
+		unlink(doors, req.Door)
+		unlink(doors, req.Target)
+		from, to := doors[req.Door], doors[req.Target]
+		from.Link, to.Link = req.Target, req.Door
+		doors[req.Door], doors[req.Target] = from, to
+	case ActionDeadEnd:
+		annotation := doors[req.Door]
+		annotation.DeadEnd = true
+		doors[req.Door] = annotation
+	case ActionNote:
+		annotation := doors[req.Door]
+		annotation.Note = req.Note
+		put(doors, req.Door, annotation)
+	case ActionUnmark:
+		unlink(doors, req.Door)
+		delete(doors, req.Door)
+	default:
+		return Set{}, fmt.Errorf("%w: action must be one of %s, %s, %s or %s", ErrInvalid,
+			ActionLink, ActionDeadEnd, ActionNote, ActionUnmark)
+	}
+
+	updated := Set{Seed: req.Seed, Doors: doors}
+	if err := persist.Save(persist.SeedFile(Dir, req.Seed), updated); err != nil {
+		return Set{}, err
+	}
+	cache[req.Seed] = updated
+	return updated, nil
+}
+
+// Merge returns save with its seed's annotations applied. a seed whose annotations can't be read is served as is
+func Merge(save tracker.Save) tracker.Save {
+	set, err := Get(save.Debug.Seed)
+	if err != nil {
+		log.Log.Warn("Failed to read annotations, serving the state without them",
+			zap.String("seed", save.Debug.Seed),
+			zap.Error(err),
+		)
+		return save
+	}
+	return tracker.Annotate(save, set.Doors)
+}
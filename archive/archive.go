@@ -0,0 +1,147 @@
+package archive
+
+import (
+	"entrance1/log"
+	"entrance1/persist"
+	"entrance1/store"
+	"entrance1/tracker"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type (
+	// Entry is the last known state of a seed
+	Entry struct {
+		Seed      string
+		FirstSeen time.Time
+		LastSeen  time.Time
+		Save      tracker.Save
+	}
+	// Summary is an Entry without its state, for listing
+	Summary struct {
+		Seed      string
+		FirstSeen time.Time
+		LastSeen  time.Time
+		Name      string
+		Totals    tracker.Totals
+	}
+)
+
+const Dir = "seeds"
+
+var (
+	mu sync.Mutex
+	// firstSeen caches when the seed being played was first archived, so each update doesn't re-read its file
+	firstSeen = map[string]time.Time{}
+)
+
+// Record stores save as the latest state of its seed. snapshots taken while the save and spoiler log disagree
+// are skipped, whatever the policy: they are either missing the spoiler log or carrying another seed's, and
+// would overwrite the real final state
+func Record(save tracker.Save, now time.Time) {
+	seed := save.Debug.Seed
+	if seed == "" || save.Mismatch.Active {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	first, ok := firstSeen[seed]
+	if !ok {
+		first = now
+		existing := Entry{}
+		if err := persist.Load(persist.SeedFile(Dir, seed), &existing); err == nil {
+			first = existing.FirstSeen
+		}
+		firstSeen[seed] = first
+	}
+
+	entry := Entry{
+		Seed:      seed,
+		FirstSeen: first,
+		LastSeen:  now,
+		Save:      save,
+	}
+	if err := persist.Save(persist.SeedFile(Dir, seed), entry); err != nil {
+		log.Log.Error("Failed to archive seed",
+			zap.String("seed", seed),
+			zap.Error(err),
+		)
+	}
+}
+
+// List summarizes every archived seed, most recently played first
+func List() ([]Summary, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	summaries := []Summary{}
+	files, err := os.ReadDir(Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return summaries, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		entry := Entry{}
+		if err := persist.Load(filepath.Join(Dir, file.Name()), &entry); err != nil {
+			log.Log.Warn("Skipping unreadable seed archive",
+				zap.String("file", file.Name()),
+				zap.Error(err),
+			)
+			continue
+		}
+		summaries = append(summaries, Summary{
+			Seed:      entry.Seed,
+			FirstSeen: entry.FirstSeen,
+			LastSeen:  entry.LastSeen,
+			Name:      entry.Save.Debug.Name,
+			Totals:    entry.Save.Totals,
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].LastSeen.After(summaries[j].LastSeen)
+	})
+	return summaries, nil
+}
+
+// Get returns the archived state of a seed. a seed that was never archived is reported with os.ErrNotExist
+func Get(seed string) (Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	entry := Entry{}
+	if err := persist.Load(persist.SeedFile(Dir, seed), &entry); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+// Delete removes a seed from the archive. a seed that was never archived is reported with os.ErrNotExist
+func Delete(seed string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	delete(firstSeen, seed)
+	return os.Remove(persist.SeedFile(Dir, seed))
+}
+
+// Watch archives every snapshot published to state until the process exits
+func Watch(state *store.Store[tracker.Save]) {
+	sub := state.Subscribe()
+	defer sub.Close()
+	for save := range sub.C {
+		Record(save, time.Now())
+	}
+}
@@ -5,6 +5,7 @@ go 1.18
 require (
 	github.com/labstack/echo/v4 v4.11.4
 	go.uber.org/zap v1.27.0
+	golang.org/x/sys v0.15.0
 )
 
 require (
@@ -17,7 +18,6 @@ require (
 	go.uber.org/multierr v1.11.0 // indirect
 	golang.org/x/crypto v0.17.0 // indirect
 	golang.org/x/net v0.19.0 // indirect
-	golang.org/x/sys v0.15.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 	golang.org/x/time v0.5.0 // indirect
 )
@@ -0,0 +1,98 @@
+package health
+
+import (
+	"entrance1/metrics"
+	"entrance1/store"
+	"entrance1/tracker"
+	"time"
+)
+
+type (
+	// Poll is what the poller last saw on disk
+	Poll struct {
+		SavesFound   bool
+		SpoilerFound bool
+		// TrackedSave is the save file being followed, empty when there is none
+		TrackedSave string
+		// LastError is the most recent thing that went wrong, cleared once a poll gets all the way through
+		LastError     string
+		LastErrorTime time.Time
+	}
+	// Report is everything /health returns
+	Report struct {
+		// Ready is set once a save is being tracked without errors
+		Ready bool
+		// Problems explains in plain words everything that isn't right, including what doesn't stop Ready
+		Problems            []string
+		Poll                Poll
+		LastParse           time.Time
+		ConsecutiveFailures int
+		Mismatch            tracker.Mismatch
+	}
+)
+
+// State is updated by the poller on every pass
+var State = store.New(Poll{})
+
+// Failed records an error seen by the poller
+func Failed(err error) {
+	State.Update(func(poll Poll) Poll {
+		poll.LastError = err.Error()
+		poll.LastErrorTime = time.Now()
+		return poll
+	})
+}
+
+// Succeeded clears the last error once a poll got all the way through
+func Succeeded() {
+	State.Update(func(poll Poll) Poll {
+		poll.LastError = ""
+		return poll
+	})
+}
+
+// Found records whether the SAVES directory could be read and which save in it is being tracked
+func Found(saves bool, tracked string) {
+	State.Update(func(poll Poll) Poll {
+		poll.SavesFound = saves
+		poll.TrackedSave = tracked
+		return poll
+	})
+}
+
+// FoundSpoiler records whether the spoiler log could be read
+func FoundSpoiler(found bool) {
+	State.Update(func(poll Poll) Poll {
+		poll.SpoilerFound = found
+		return poll
+	})
+}
+
+// Check puts together the poller status with the state being served
+func Check(save tracker.Save) Report {
+	report := Report{
+		Problems:            []string{},
+		Poll:                State.Get(),
+		LastParse:           metrics.LastSuccess(),
+		ConsecutiveFailures: metrics.ConsecutiveFailures(),
+		Mismatch:            save.Mismatch,
+	}
+
+	if !report.Poll.SavesFound {
+		report.Problems = append(report.Problems, "SAVES directory not found, check secretLegend in settings")
+	} else if report.Poll.TrackedSave == "" {
+		report.Problems = append(report.Problems, "no .tunic save files found")
+	}
+	if !report.Poll.SpoilerFound {
+		report.Problems = append(report.Problems, "Spoiler.log not found, only spoiler-free data is available")
+	}
+	if report.Poll.LastError != "" {
+		report.Problems = append(report.Problems, report.Poll.LastError)
+	}
+	if report.Mismatch.Active {
+		report.Problems = append(report.Problems, "save seed does not match spoiler seed")
+	}
+	// a missing spoiler or a mismatch still leaves something worth serving
+	report.Ready = report.Poll.SavesFound && report.Poll.TrackedSave != "" && report.Poll.LastError == ""
+	return report
+}
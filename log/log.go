@@ -1,6 +1,7 @@
 package log
 
 import (
+	"io"
 	"os"
 
 	"go.uber.org/zap"
@@ -12,11 +13,16 @@ var (
 )
 
 func Initialize() {
+	InitializeWriter(os.Stdout)
+}
+
+// InitializeWriter sets up logging to w, for commands whose own output goes to stdout
+func InitializeWriter(w io.Writer) {
 	// set up logging
 	pe := zap.NewProductionEncoderConfig()
 	pe.EncodeTime = zapcore.ISO8601TimeEncoder
 	consoleEncoder := zapcore.NewConsoleEncoder(pe)
 	level := zap.DebugLevel
-	core := zapcore.NewCore(consoleEncoder, zapcore.AddSync(os.Stdout), level)
+	core := zapcore.NewCore(consoleEncoder, zapcore.AddSync(w), level)
 	Log = zap.New(core)
 }
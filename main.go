@@ -1,121 +1,221 @@
 package main
 
 import (
+	"encoding/json"
+	"entrance1/archive"
 	"entrance1/log"
 	"entrance1/server"
 	"entrance1/settings"
+	"entrance1/timeline"
 	"entrance1/tracker"
+	"flag"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"go.uber.org/zap"
 )
 
-func main() {
-	log.Initialize()
-	settings.Load()
+const version = "Assay"
+
+const usage = `Tunic Transition Tracker %s
+
+usage: %s [command] [flags]
+
+commands:
+  serve     poll the save and spoiler log and serve them over http (the default)
+  parse     print the tracker state for a save and spoiler log once
+  watch     poll the save and spoiler log and print every discovery
+  validate  check the settings and data tables
 
-	version := "Assay"
+run a command with -h to see its flags
+`
+
+// command runs a subcommand with the arguments that follow its name, returning the exit code
+type command func(args []string) int
+
+var commands = map[string]command{
+	"serve":    serve,
+	"parse":    parse,
+	"watch":    watch,
+	"validate": validate,
+}
+
+// settingsFlags adds the flags every command that reads the settings file shares. the returned function loads
+// the settings and applies the overrides once the flags have been parsed
+func settingsFlags(fs *flag.FlagSet) func() {
+	config := fs.String("config", settings.Path, "settings file to read")
+	address := fs.String("address", "", "address to listen on, overriding the settings file")
+	secretLegend := fs.String("secret-legend", "", "Secret Legend data directory, overriding the settings file")
+	return func() {
+		settings.Path = *config
+		settings.Load()
+		// overrides only live in memory, the settings file is left as it was
+		settings.Override(settings.Settings{
+			Address:      *address,
+			SecretLegend: *secretLegend,
+		})
+	}
+}
+
+// loadPacks adds any data packs dropped next to the binary to the embedded ones
+func loadPacks() {
+	if err := tracker.LoadDataPacks(tracker.PackDir()); err != nil {
+		log.Log.Error("Failed to load data packs, using the embedded ones",
+			zap.String("dir", tracker.PackDir()),
+			zap.Error(err),
+		)
+	}
+}
+
+func serve(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	load := settingsFlags(fs)
+	fs.Parse(args)
+
+	log.Initialize()
+	load()
+	loadPacks()
 
 	log.Log.Info("Welcome to the Tunic Transition Tracker!",
-		zap.String("path", settings.State.SecretLegend),
-		zap.String("listener", settings.State.Address),
+		zap.String("path", settings.State.Get().SecretLegend),
+		zap.String("listener", settings.State.Get().Address),
 		zap.String("version", version),
 	)
-	// poll for updates on a set interval
-	tick := time.NewTicker(200 * time.Millisecond)
-	go func() {
-		consecutiveFailures := 0
-		noDirWarningArm := true
-		noSaveWarningArm := true
-
-		for {
-			// wait for the timer to tick over
-			<-tick.C
-			spoiler := filepath.Join(settings.State.SecretLegend, "Randomizer", "Spoiler.log")
-			saves := filepath.Join(settings.State.SecretLegend, "SAVES")
-
-			// read all existing saves to get most recent
-			check := ""
-			mostRecentMod := time.Time{}
-			files, err := os.ReadDir(saves)
-			if err != nil {
-				// warn about saves but don't spam
-				if noDirWarningArm {
-					log.Log.Error("Could not read tunic SAVES directory",
-						zap.String("saves", saves),
-					)
-				}
-				noDirWarningArm = false
-				continue
-			}
-			// if we get here, assume we read the directory correctly
-			noDirWarningArm = true
-
-			// iterate over each file in save directory
-			for _, file := range files {
-				name := file.Name()
-				if !strings.HasSuffix(name, ".tunic") {
-					continue
-				}
-				info, err := file.Info()
-				if err != nil {
-					// do not warn because we'd be spamming 10x a second
-					continue
-				}
-
-				if info.ModTime().After(mostRecentMod) {
-					check = name
-					mostRecentMod = info.ModTime()
-				}
-			}
-			// make sure we found at least one save file
-			if check == "" {
-				// warn about lack of saves but don't spam
-				if noSaveWarningArm {
-					log.Log.Error("Could not find any .tunic files in SAVES directory",
-						zap.String("saves", saves),
-					)
-				}
-				noSaveWarningArm = false
-				continue
-			}
-			// if we made it past the check, re-arm the no-save warning
-			noSaveWarningArm = true
-			changedSave := check != tracker.State.Debug.Name
-
-			// check the spoiler.log for updates
-			spoilerStat, err := os.Stat(spoiler)
-			if err != nil {
-				consecutiveFailures++
-				log.Log.Error("Could not poll spoiler log. File may be busy?",
-					zap.Int("failures", consecutiveFailures),
-					zap.Error(err),
-				)
-				continue
-			}
-			changedSpoiler := !tracker.State.Debug.SpoilerMod.Equal(spoilerStat.ModTime())
-
-			// run a full update if either file we care about changed
-			if changedSave || changedSpoiler {
-				log.Log.Debug("Detected update",
-					zap.Bool("save updated", changedSave),
-					zap.Bool("spoiler updated", changedSpoiler),
-					zap.String("save name", check),
-					zap.Time("spoiler update", spoilerStat.ModTime()),
-				)
-				if err := tracker.ParseWithSpoiler(check, saves, spoiler); err != nil {
-					log.Log.Error("Error attempting to parse save state",
-						zap.Error(err),
-					)
-				}
-			}
-			// if we made it to the end, it was a successful update
-			consecutiveFailures = 0
-		}
-	}()
+	// keep a history of discoveries for every seed we see
+	go timeline.Watch(tracker.State)
+	// and the latest state of each of them
+	go archive.Watch(tracker.State)
 
+	go poll()
 	server.Listen()
+	return 1
+}
+
+func parse(args []string) int {
+	fs := flag.NewFlagSet("parse", flag.ExitOnError)
+	savePath := fs.String("save", "", "save file to parse (required)")
+	spoilerPath := fs.String("spoiler", "", "spoiler log to combine with the save, leave out for the spoiler-free state")
+	policy := fs.String("mismatch-policy", "", "what to print when the seeds differ: saveonly, withhold or serve")
+	fs.Parse(args)
+
+	// stdout is for the state alone
+	log.InitializeWriter(os.Stderr)
+	loadPacks()
+	if *savePath == "" {
+		fmt.Fprintln(os.Stderr, "parse needs --save")
+		fs.Usage()
+		return 2
+	}
+
+	saveReader, err := os.Open(*savePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not open save: %v\n", err)
+		return 1
+	}
+	save, err := tracker.ParseSaveFile(saveReader)
+	saveReader.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not parse save: %v\n", err)
+		return 1
+	}
+
+	var payload tracker.Save
+	var warnings []tracker.Warning
+	if *spoilerPath == "" {
+		payload, warnings = tracker.Combine(nil, save)
+	} else {
+		spoilerReader, err := os.Open(*spoilerPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not open spoiler log: %v\n", err)
+			return 1
+		}
+		spoiler, err := tracker.ParseSpoilerLog(spoilerReader)
+		spoilerReader.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not parse spoiler log: %v\n", err)
+			return 1
+		}
+		payload, warnings = tracker.Combine(&spoiler, save)
+		payload = tracker.ApplyMismatch(payload, save, *policy)
+	}
+	payload.Debug.Name = filepath.Base(*savePath)
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s %v\n", warning.Message, warning.Details)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "	")
+	if err := encoder.Encode(payload); err != nil {
+		fmt.Fprintf(os.Stderr, "could not write state: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// printDiff writes one line per discovery between two states
+func printDiff(w io.Writer, prev, next tracker.Save) {
+	if next.Debug.Seed != prev.Debug.Seed || next.Debug.Name != prev.Debug.Name {
+		fmt.Fprintf(w, "%s\t%s\t%s\tseed %s\n", time.Now().Format(time.RFC3339), timeline.KindStarted, next.Debug.Name, next.Debug.Seed)
+		// a new save starts from nothing, so everything in it is news
+		prev = tracker.Save{}
+	}
+	for _, event := range timeline.Diff(prev, next) {
+		line := fmt.Sprintf("%s\t%s\t%s\t%s", time.Now().Format(time.RFC3339), event.Kind, event.Scene, event.Name)
+		if event.Detail != "" {
+			line += "\t" + event.Detail
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+func watch(args []string) int {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	load := settingsFlags(fs)
+	noSpoiler := fs.Bool("nospoiler", false, "follow the spoiler-free state instead")
+	fs.Parse(args)
+
+	// stdout is for discoveries alone
+	log.InitializeWriter(os.Stderr)
+	load()
+	loadPacks()
+
+	state := tracker.State
+	if *noSpoiler {
+		state = tracker.NoSpoilerState
+	}
+	sub := state.Subscribe()
+	defer sub.Close()
+	go poll()
+
+	prev := tracker.Save{}
+	mismatched := false
+	for next := range sub.C {
+		if next.Mismatch.Active != mismatched {
+			mismatched = next.Mismatch.Active
+			fmt.Printf("%s\tmismatch\tsave seed %s\tspoiler seed %s\n", time.Now().Format(time.RFC3339), next.Mismatch.SaveSeed, next.Mismatch.SpoilerSeed)
+		}
+		// a withheld state is missing everything the spoiler gave, so keep diffing against the last good one
+		if mismatched && next.Mismatch.Policy != tracker.MismatchServe {
+			continue
+		}
+		printDiff(os.Stdout, prev, next)
+		prev = next
+	}
+	return 0
+}
+
+func main() {
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		name, args = args[0], args[1:]
+	}
+	run, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, usage, version, os.Args[0])
+		os.Exit(2)
+	}
+	os.Exit(run(args))
 }
@@ -0,0 +1,193 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// Sample is one value of a metric
+	Sample struct {
+		Labels map[string]string
+		Value  float64
+	}
+	// Gauge is a metric whose value is worked out by the caller when metrics are written
+	Gauge struct {
+		Name    string
+		Help    string
+		Samples []Sample
+	}
+	histogram struct {
+		// counts holds how many observations fell at or under each of parseBuckets, +Inf is count itself
+		counts []uint64
+		sum    float64
+		count  uint64
+	}
+)
+
+// what was being done when something was observed
+const (
+	KindSpoiler   = "spoiler"
+	KindNoSpoiler = "nospoiler"
+	KindPoll      = "poll"
+)
+
+// Prefix is put in front of every metric name
+const Prefix = "tunic_tracker_"
+
+// parseBuckets are the upper bounds of the parse duration histogram, in seconds
+var parseBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+var (
+	mu                  sync.Mutex
+	durations           = map[string]*histogram{}
+	parses              = map[string]uint64{}
+	failures            = map[string]uint64{}
+	consecutiveFailures int
+	lastSuccess         time.Time
+)
+
+// ObserveParse records how long a parse took and whether it worked
+func ObserveParse(kind string, took time.Duration, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	h, ok := durations[kind]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(parseBuckets))}
+		durations[kind] = h
+	}
+	seconds := took.Seconds()
+	for i, bound := range parseBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+
+	parses[kind]++
+	if err != nil {
+		failures[kind]++
+		return
+	}
+	lastSuccess = time.Now()
+}
+
+// ObserveError records a failure that happened outside of a parse
+func ObserveError(kind string) {
+	mu.Lock()
+	defer mu.Unlock()
+	failures[kind]++
+}
+
+// SetConsecutiveFailures records how many polls in a row have failed
+func SetConsecutiveFailures(n int) {
+	mu.Lock()
+	defer mu.Unlock()
+	consecutiveFailures = n
+}
+
+// LastSuccess returns when a parse last worked, zero if none has yet
+func LastSuccess() time.Time {
+	mu.Lock()
+	defer mu.Unlock()
+	return lastSuccess
+}
+
+// ConsecutiveFailures returns how many polls in a row have failed
+func ConsecutiveFailures() int {
+	mu.Lock()
+	defer mu.Unlock()
+	return consecutiveFailures
+}
+
+// escape quotes a label value the way the text format expects
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// formatLabels renders labels sorted by name, with extra appended as the last pair
+func formatLabels(labels map[string]string, extra ...string) string {
+	pairs := []string{}
+	for _, name := range sortedKeys(labels) {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, escape(labels[name])))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[i], escape(extra[i+1])))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func writeHeader(b *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s%s %s\n# TYPE %s%s %s\n", Prefix, name, help, Prefix, name, kind)
+}
+
+func writeCounter(b *strings.Builder, name, help string, values map[string]uint64) {
+	writeHeader(b, name, "counter", help)
+	for _, kind := range sortedKeys(values) {
+		fmt.Fprintf(b, "%s%s%s %d\n", Prefix, name, formatLabels(map[string]string{"kind": kind}), values[kind])
+	}
+}
+
+// Write writes every recorded metric, followed by the gauges worked out by the caller, in the prometheus
+// text exposition format
+func Write(w io.Writer, gauges []Gauge) error {
+	b := &strings.Builder{}
+
+	mu.Lock()
+	writeHeader(b, "parse_duration_seconds", "histogram", "Time taken to parse the save and spoiler log.")
+	for _, kind := range sortedKeys(durations) {
+		h := durations[kind]
+		labels := map[string]string{"kind": kind}
+		for i, bound := range parseBuckets {
+			fmt.Fprintf(b, "%sparse_duration_seconds_bucket%s %d\n", Prefix, formatLabels(labels, "le", formatFloat(bound)), h.counts[i])
+		}
+		fmt.Fprintf(b, "%sparse_duration_seconds_bucket%s %d\n", Prefix, formatLabels(labels, "le", "+Inf"), h.count)
+		fmt.Fprintf(b, "%sparse_duration_seconds_sum%s %s\n", Prefix, formatLabels(labels), formatFloat(h.sum))
+		fmt.Fprintf(b, "%sparse_duration_seconds_count%s %d\n", Prefix, formatLabels(labels), h.count)
+	}
+	writeCounter(b, "parses_total", "Parses attempted.", parses)
+	writeCounter(b, "errors_total", "Parses and polls that failed.", failures)
+
+	writeHeader(b, "consecutive_failures", "gauge", "Polls in a row that failed to read the spoiler log.")
+	fmt.Fprintf(b, "%sconsecutive_failures %d\n", Prefix, consecutiveFailures)
+	writeHeader(b, "last_success_timestamp_seconds", "gauge", "Unix time of the last successful parse, 0 if there hasn't been one.")
+	last := 0.0
+	if !lastSuccess.IsZero() {
+		last = float64(lastSuccess.UnixMilli()) / 1000
+	}
+	fmt.Fprintf(b, "%slast_success_timestamp_seconds %s\n", Prefix, formatFloat(last))
+	mu.Unlock()
+
+	for _, gauge := range gauges {
+		writeHeader(b, gauge.Name, "gauge", gauge.Help)
+		for _, sample := range gauge.Samples {
+			fmt.Fprintf(b, "%s%s%s %s\n", Prefix, gauge.Name, formatLabels(sample.Labels), formatFloat(sample.Value))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,220 @@
+package notes
+
+import (
+	"entrance1/persist"
+	"entrance1/tracker"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// Note is free text left on a scene, or on one check in it when Check is set.
+	// Scene and Check use the same names as Save.Scenes and Scene.Checks
+	Note struct {
+		ID      int
+		Scene   string
+		Check   string `json:",omitempty"`
+		Text    string
+		Tags    []string
+		Created time.Time
+		Updated time.Time
+	}
+	// Book is every note left in a seed
+	Book struct {
+		Seed string
+		// Next is the id given to the next note
+		Next  int
+		Notes []Note
+	}
+	// Request creates or edits a note
+	Request struct {
+		// Seed defaults to the seed being tracked
+		Seed  string   `json:"seed"`
+		Scene string   `json:"scene"`
+		Check string   `json:"check"`
+		Text  string   `json:"text"`
+		Tags  []string `json:"tags"`
+	}
+)
+
+const Dir = "notebooks"
+
+var (
+	// ErrInvalid is returned for notes that can't be stored, wrapped with the reason
+	ErrInvalid = errors.New("invalid note")
+	// ErrNotFound is returned when editing or deleting a note that doesn't exist
+	ErrNotFound = errors.New("note not found")
+)
+
+var (
+	mu sync.Mutex
+	// cache holds every seed read so far
+	cache = map[string]Book{}
+)
+
+// load returns the notes for seed, reading them from disk the first time. callers hold mu
+func load(seed string) (Book, error) {
+	if book, ok := cache[seed]; ok {
+		return book, nil
+	}
+	book := Book{Seed: seed, Next: 1}
+	if err := persist.Load(persist.SeedFile(Dir, seed), &book); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Book{}, err
+	}
+	cache[seed] = book
+	return book, nil
+}
+
+// store saves book and replaces the cached copy. callers hold mu
+func store(book Book) error {
+	if err := persist.Save(persist.SeedFile(Dir, book.Seed), book); err != nil {
+		return err
+	}
+	cache[book.Seed] = book
+	return nil
+}
+
+// cleanTags lowercases, trims and dedupes tags so filtering doesn't depend on how they were typed
+func cleanTags(tags []string) []string {
+	seen := map[string]bool{}
+	cleaned := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		cleaned = append(cleaned, tag)
+	}
+	sort.Strings(cleaned)
+	return cleaned
+}
+
+// Validate checks a note's scene and check against save. checks are only known with a spoiler log, so a
+// scene without any is taken at its word
+func Validate(save tracker.Save, scene, check string) error {
+	found, ok := save.Scenes[scene]
+	if !ok {
+		return fmt.Errorf("%w: unrecognized scene: %s", ErrInvalid, scene)
+	}
+	if check == "" || len(found.Checks) == 0 {
+		return nil
+	}
+	if _, ok := found.Checks[check]; !ok {
+		return fmt.Errorf("%w: unrecognized check in %s: %s", ErrInvalid, scene, check)
+	}
+	return nil
+}
+
+// List returns the notes of a seed, oldest first. an empty tag returns every note
+func List(seed, tag string) ([]Note, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	book, err := load(seed)
+	if err != nil {
+		return nil, err
+	}
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	matching := []Note{}
+	for _, note := range book.Notes {
+		if tag == "" {
+			matching = append(matching, note)
+			continue
+		}
+		for _, current := range note.Tags {
+			if current == tag {
+				matching = append(matching, note)
+				break
+			}
+		}
+	}
+	return matching, nil
+}
+
+// Add stores a new note
+func Add(req Request, now time.Time) (Note, error) {
+	if req.Seed == "" {
+		return Note{}, fmt.Errorf("%w: no seed is being tracked", ErrInvalid)
+	}
+	if req.Scene == "" {
+		return Note{}, fmt.Errorf("%w: a note needs a scene", ErrInvalid)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	book, err := load(req.Seed)
+	if err != nil {
+		return Note{}, err
+	}
+	note := Note{
+		ID:      book.Next,
+		Scene:   req.Scene,
+		Check:   req.Check,
+		Text:    req.Text,
+		Tags:    cleanTags(req.Tags),
+		Created: now,
+		Updated: now,
+	}
+	book.Next++
+	book.Notes = append(append([]Note{}, book.Notes...), note)
+	if err := store(book); err != nil {
+		return Note{}, err
+	}
+	return note, nil
+}
+
+// Edit replaces the text and tags of a note. its scene and check never change
+func Edit(seed string, id int, req Request, now time.Time) (Note, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	book, err := load(seed)
+	if err != nil {
+		return Note{}, err
+	}
+	updated := append([]Note{}, book.Notes...)
+	for i, note := range updated {
+		if note.ID != id {
+			continue
+		}
+		note.Text = req.Text
+		note.Tags = cleanTags(req.Tags)
+		note.Updated = now
+		updated[i] = note
+		book.Notes = updated
+		if err := store(book); err != nil {
+			return Note{}, err
+		}
+		return note, nil
+	}
+	return Note{}, ErrNotFound
+}
+
+// Delete removes a note
+func Delete(seed string, id int) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	book, err := load(seed)
+	if err != nil {
+		return err
+	}
+	remaining := []Note{}
+	for _, note := range book.Notes {
+		if note.ID != id {
+			remaining = append(remaining, note)
+		}
+	}
+	if len(remaining) == len(book.Notes) {
+		return ErrNotFound
+	}
+	book.Notes = remaining
+	return store(book)
+}
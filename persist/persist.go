@@ -0,0 +1,53 @@
+package persist
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SeedFile returns the json file used to store per-seed data in dir
+func SeedFile(dir, seed string) string {
+	// seeds are numeric today, but never let one escape the directory
+	clean := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == '.' {
+			return '_'
+		}
+		return r
+	}, seed)
+	return filepath.Join(dir, clean+".json")
+}
+
+// Load reads a json file into v. a missing file is reported with os.ErrNotExist
+func Load(path string, v interface{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("Failed to decode %s: %w", path, err)
+	}
+	return nil
+}
+
+// Save writes v as json, going through a temporary file so a crash never leaves half a file behind
+func Save(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("Failed to create directory for %s: %w", path, err)
+	}
+	q, err := json.MarshalIndent(v, "", "	")
+	if err != nil {
+		return fmt.Errorf("Failed to marshall %s: %w", path, err)
+	}
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, q, os.ModePerm); err != nil {
+		return fmt.Errorf("Failed to write %s: %w", temp, err)
+	}
+	if err := os.Rename(temp, path); err != nil {
+		return fmt.Errorf("Failed to replace %s: %w", path, err)
+	}
+	return nil
+}
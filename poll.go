@@ -0,0 +1,199 @@
+package main
+
+import (
+	"entrance1/health"
+	"entrance1/log"
+	"entrance1/metrics"
+	"entrance1/settings"
+	"entrance1/tracker"
+	"entrance1/watcher"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// poll keeps tracker.State and tracker.NoSpoilerState up to date with the files in the secret legend
+// directory. it never returns
+func poll() {
+	// wake up whenever the saves or the spoiler log change
+	watch := watcher.New(func() []string {
+		secretLegend := settings.State.Get().SecretLegend
+		return []string{
+			filepath.Join(secretLegend, "SAVES"),
+			filepath.Join(secretLegend, "Randomizer"),
+		}
+	}, watcher.Debounce)
+	// settings can change which directory or save we track, so take another look whenever they do
+	go func() {
+		sub := settings.State.Subscribe()
+		defer sub.Close()
+		for range sub.C {
+			watch.Retry()
+		}
+	}()
+
+	consecutiveFailures := 0
+	noDirWarningArm := true
+	noSaveWarningArm := true
+	noPinWarningArm := true
+
+	for {
+		// wait for something to change on disk
+		<-watch.C
+		current := settings.State.Get()
+		spoiler := filepath.Join(current.SecretLegend, "Randomizer", "Spoiler.log")
+		saves := filepath.Join(current.SecretLegend, "SAVES")
+
+		// read all existing saves to get most recent
+		check := ""
+		mostRecentMod := time.Time{}
+		pinnedMod := time.Time{}
+		foundPinned := false
+		files, err := os.ReadDir(saves)
+		if err != nil {
+			// warn about saves but don't spam
+			if noDirWarningArm {
+				log.Log.Error("Could not read tunic SAVES directory",
+					zap.String("saves", saves),
+				)
+			}
+			noDirWarningArm = false
+			health.Found(false, "")
+			continue
+		}
+		// if we get here, assume we read the directory correctly
+		noDirWarningArm = true
+
+		// iterate over each file in save directory
+		for _, file := range files {
+			name := file.Name()
+			if !strings.HasSuffix(name, ".tunic") {
+				continue
+			}
+			info, err := file.Info()
+			if err != nil {
+				// do not warn because we'd be spamming 10x a second
+				continue
+			}
+
+			if name == current.PinnedSave {
+				foundPinned = true
+				pinnedMod = info.ModTime()
+			}
+			if info.ModTime().After(mostRecentMod) {
+				check = name
+				mostRecentMod = info.ModTime()
+			}
+		}
+		// a pinned save wins over the most recent one, as long as it still exists
+		if foundPinned {
+			check = current.PinnedSave
+			mostRecentMod = pinnedMod
+			noPinWarningArm = true
+		} else if current.PinnedSave != "" {
+			if noPinWarningArm {
+				log.Log.Warn("Pinned save not found, tracking the most recent save instead",
+					zap.String("pinned", current.PinnedSave),
+				)
+			}
+			noPinWarningArm = false
+		}
+		// make sure we found at least one save file
+		if check == "" {
+			// warn about lack of saves but don't spam
+			if noSaveWarningArm {
+				log.Log.Error("Could not find any .tunic files in SAVES directory",
+					zap.String("saves", saves),
+				)
+			}
+			noSaveWarningArm = false
+			health.Found(true, "")
+			continue
+		}
+		// if we made it past the check, re-arm the no-save warning
+		noSaveWarningArm = true
+		health.Found(true, check)
+		// only a clean pass all the way through clears the last error
+		failed := false
+		tracked := tracker.State.Get()
+		changedSave := check != tracked.Debug.Name || !mostRecentMod.Equal(tracked.Debug.SaveMod)
+
+		// the spoiler-free state only depends on the save itself, so keep it fresh even without a spoiler log
+		noSpoiler := tracker.NoSpoilerState.Get()
+		if check != noSpoiler.Debug.Name || !mostRecentMod.Equal(noSpoiler.Debug.SaveMod) {
+			start := time.Now()
+			err := tracker.ParseWithoutSpoiler(check, saves)
+			metrics.ObserveParse(metrics.KindNoSpoiler, time.Since(start), err)
+			if err != nil {
+				log.Log.Error("Error attempting to parse save state without spoiler",
+					zap.Error(err),
+				)
+				health.Failed(err)
+				failed = true
+			}
+		}
+
+		// check the spoiler.log for updates
+		spoilerStat, err := os.Stat(spoiler)
+		health.FoundSpoiler(err == nil)
+		if os.IsNotExist(err) {
+			// no spoiler log is a normal way to play, so it doesn't count as a failure. it will show up as a
+			// directory change when it's written
+			log.Log.Warn("Could not find spoiler log, only the spoiler-free state is being updated",
+				zap.String("spoiler", spoiler),
+			)
+			consecutiveFailures = 0
+			metrics.SetConsecutiveFailures(consecutiveFailures)
+			if !failed {
+				// the save alone parsed fine
+				health.Succeeded()
+			}
+			continue
+		} else if err != nil {
+			consecutiveFailures++
+			metrics.ObserveError(metrics.KindPoll)
+			metrics.SetConsecutiveFailures(consecutiveFailures)
+			log.Log.Error("Could not poll spoiler log. File may be busy?",
+				zap.Int("failures", consecutiveFailures),
+				zap.Error(err),
+			)
+			// anything else might clear up on its own
+			health.Failed(err)
+			watch.Retry()
+			continue
+		}
+		changedSpoiler := !tracked.Debug.SpoilerMod.Equal(spoilerStat.ModTime())
+		changedPolicy := tracked.Mismatch.Policy != tracker.MismatchPolicy(current.MismatchPolicy)
+
+		// run a full update if either file we care about changed
+		if changedSave || changedSpoiler || changedPolicy {
+			log.Log.Debug("Detected update",
+				zap.Bool("save updated", changedSave),
+				zap.Bool("spoiler updated", changedSpoiler),
+				zap.Bool("policy updated", changedPolicy),
+				zap.String("save name", check),
+				zap.Time("spoiler update", spoilerStat.ModTime()),
+			)
+			start := time.Now()
+			err := tracker.ParseWithSpoiler(check, saves, spoiler, current.MismatchPolicy)
+			metrics.ObserveParse(metrics.KindSpoiler, time.Since(start), err)
+			if err != nil {
+				log.Log.Error("Error attempting to parse save state",
+					zap.Error(err),
+				)
+				health.Failed(err)
+				failed = true
+				watch.Retry()
+			}
+		}
+		// if we made it to the end, it was a successful update
+		consecutiveFailures = 0
+		metrics.SetConsecutiveFailures(consecutiveFailures)
+		if !failed {
+			health.Succeeded()
+		}
+	}
+}
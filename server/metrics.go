@@ -0,0 +1,46 @@
+package server
+
+import (
+	"entrance1/metrics"
+	"entrance1/store"
+	"entrance1/tracker"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/labstack/echo/v4"
+)
+
+// progressGauges reports discovered and total entrances and checks for each view of the save
+func progressGauges(views map[string]*store.Store[tracker.Save]) []metrics.Gauge {
+	entrances := metrics.Gauge{Name: "entrances", Help: "Entrances in the seed."}
+	entrancesFound := metrics.Gauge{Name: "entrances_discovered", Help: "Entrances discovered so far."}
+	checks := metrics.Gauge{Name: "checks", Help: "Checks in the seed."}
+	checksFound := metrics.Gauge{Name: "checks_discovered", Help: "Checks found so far."}
+	for _, view := range []string{metrics.KindSpoiler, metrics.KindNoSpoiler} {
+		totals := views[view].Get().Totals
+		labels := map[string]string{"view": view}
+		entrances.Samples = append(entrances.Samples, metrics.Sample{Labels: labels, Value: float64(totals.Entrances.Total)})
+		entrancesFound.Samples = append(entrancesFound.Samples, metrics.Sample{Labels: labels, Value: float64(totals.Entrances.Total - totals.Entrances.Undiscovered)})
+		checks.Samples = append(checks.Samples, metrics.Sample{Labels: labels, Value: float64(totals.Checks.Total)})
+		checksFound.Samples = append(checksFound.Samples, metrics.Sample{Labels: labels, Value: float64(totals.Checks.Total - totals.Checks.Undiscovered)})
+	}
+	return []metrics.Gauge{entrances, entrancesFound, checks, checksFound}
+}
+
+// serveMetrics writes everything in the prometheus text exposition format
+func serveMetrics(c echo.Context) error {
+	gauges := []metrics.Gauge{{
+		Name:    "stream_clients",
+		Help:    "Event stream clients connected.",
+		Samples: []metrics.Sample{{Value: float64(atomic.LoadInt32(&streamClients))}},
+	}}
+	gauges = append(gauges, progressGauges(map[string]*store.Store[tracker.Save]{
+		metrics.KindSpoiler:   tracker.State,
+		metrics.KindNoSpoiler: tracker.NoSpoilerState,
+	})...)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+	res.WriteHeader(http.StatusOK)
+	return metrics.Write(res, gauges)
+}
@@ -2,17 +2,46 @@ package server
 
 import (
 	"encoding/json"
+	"entrance1/annotations"
+	"entrance1/archive"
+	"entrance1/health"
 	"entrance1/log"
+	"entrance1/notes"
 	"entrance1/settings"
+	"entrance1/store"
+	"entrance1/timeline"
 	"entrance1/tracker"
+	"errors"
+	"io"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"go.uber.org/zap"
 )
 
+type (
+	saveListing struct {
+		tracker.SaveListing
+		Pinned  bool
+		Tracked bool
+	}
+	// notedSave is the /spoiler blob with the seed's notes attached
+	notedSave struct {
+		tracker.Save
+		Notes []notes.Note
+	}
+	pinRequest struct {
+		// Name of the save file to pin, or empty to go back to following the most recent save
+		Name string `json:"name"`
+	}
+)
+
 func Listen() {
 	e := echo.New()
 	e.HideBanner = true
@@ -25,54 +54,380 @@ func Listen() {
 	e.Static("/", "frontend/")
 
 	e.GET("/spoiler", func(c echo.Context) error {
-		return c.JSON(http.StatusOK, tracker.State)
+		save := annotations.Merge(tracker.State.Get())
+		if c.QueryParam("notes") != "true" {
+			return c.JSON(http.StatusOK, save)
+		}
+		found, err := notes.List(save.Debug.Seed, "")
+		if err != nil {
+			log.Log.Error("Failed to read notes",
+				zap.String("seed", save.Debug.Seed),
+				zap.Error(err),
+			)
+			return err
+		}
+		return c.JSON(http.StatusOK, notedSave{save, found})
 	})
 
-	e.GET("/settings", func(c echo.Context) error {
-		return c.JSON(http.StatusOK, settings.State)
+	e.GET("/nospoiler", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, annotations.Merge(tracker.NoSpoilerState.Get()))
 	})
 
-	e.POST("/settings", func(c echo.Context) error {
-		payload := settings.Settings{}
+	e.GET("/saves", func(c echo.Context) error {
+		current := settings.State.Get()
+		saves, err := tracker.ListSaves(filepath.Join(current.SecretLegend, "SAVES"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		tracked := tracker.State.Get().Debug.Name
+		listings := []saveListing{}
+		for _, save := range saves {
+			listings = append(listings, saveListing{
+				SaveListing: save,
+				Pinned:      save.Name == current.PinnedSave,
+				Tracked:     save.Name == tracked,
+			})
+		}
+		return c.JSON(http.StatusOK, listings)
+	})
+
+	e.POST("/saves/pin", func(c echo.Context) error {
+		payload := pinRequest{}
 		if err := c.Bind(&payload); err != nil {
-			log.Log.Error("Failed to read new settings",
+			log.Log.Error("Failed to read pin request",
+				zap.Error(err),
+			)
+			return err
+		}
+		updated, err := settings.Update(func(current settings.Settings) settings.Settings {
+			current.PinnedSave = payload.Name
+			return current
+		})
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, updated)
+	})
+
+	e.GET("/checks", func(c echo.Context) error {
+		reveal := c.QueryParam("reveal")
+		if reveal == "" {
+			reveal = tracker.RevealFound
+		}
+		checks, err := tracker.CheckItems(tracker.State.Get(), reveal)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return c.JSON(http.StatusOK, checks)
+	})
+
+	e.GET("/route", func(c echo.Context) error {
+		from := c.QueryParam("from")
+		if from == "" {
+			from = tracker.RouteCurrent
+		}
+		useDath := c.QueryParam("dath") == "true"
+		route, err := tracker.FindRoute(annotations.Merge(tracker.State.Get()), from, c.QueryParam("to"), useDath)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return c.JSON(http.StatusOK, route)
+	})
+
+	e.GET("/export/graph", func(c echo.Context) error {
+		opts := tracker.GraphOptions{}
+		for _, group := range strings.Split(c.QueryParam("group"), ",") {
+			switch group {
+			case "shops":
+				opts.GroupShops = true
+			case "farshore":
+				opts.GroupFarShore = true
+			}
+		}
+		switch c.QueryParam("format") {
+		case tracker.GraphDOT, "":
+			return c.String(http.StatusOK, tracker.ExportDOT(annotations.Merge(tracker.State.Get()), opts))
+		case tracker.GraphMermaid:
+			return c.String(http.StatusOK, tracker.ExportMermaid(annotations.Merge(tracker.State.Get()), opts))
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "format must be dot or mermaid")
+	})
+
+	e.GET("/render/map.svg", func(c echo.Context) error {
+		path := settings.State.Get().MapLayout
+		if path == "" {
+			path = "layout.json"
+		}
+		layout, err := tracker.LoadLayout(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Log.Warn("Failed to load map layout, placing every scene automatically",
+				zap.String("layout", path),
+				zap.Error(err),
+			)
+		}
+		return c.Blob(http.StatusOK, "image/svg+xml", []byte(tracker.RenderSVG(annotations.Merge(tracker.State.Get()), layout)))
+	})
+
+	e.GET("/annotations/doors", func(c echo.Context) error {
+		seed := c.QueryParam("seed")
+		if seed == "" {
+			seed = tracked().Debug.Seed
+		}
+		set, err := annotations.Get(seed)
+		if err != nil {
+			log.Log.Error("Failed to read annotations",
+				zap.String("seed", seed),
+				zap.Error(err),
+			)
+			return err
+		}
+		return c.JSON(http.StatusOK, set)
+	})
+
+	e.POST("/annotations/doors", func(c echo.Context) error {
+		payload := annotations.Request{}
+		if err := c.Bind(&payload); err != nil {
+			log.Log.Error("Failed to read annotation",
+				zap.Error(err),
+			)
+			return err
+		}
+		current := tracked()
+		if payload.Seed == "" {
+			payload.Seed = current.Debug.Seed
+		}
+		set, err := annotations.Apply(tracker.PackFor(current), payload)
+		if errors.Is(err, annotations.ErrInvalid) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		} else if err != nil {
+			log.Log.Error("Failed to save annotation",
+				zap.String("seed", payload.Seed),
+				zap.Error(err),
+			)
+			return err
+		}
+		// republish the current states so event stream clients pick up the change
+		tracker.State.Set(tracker.State.Get())
+		tracker.NoSpoilerState.Set(tracker.NoSpoilerState.Get())
+		return c.JSON(http.StatusOK, set)
+	})
+
+	e.GET("/notes", func(c echo.Context) error {
+		seed := c.QueryParam("seed")
+		if seed == "" {
+			seed = tracked().Debug.Seed
+		}
+		found, err := notes.List(seed, c.QueryParam("tag"))
+		if err != nil {
+			log.Log.Error("Failed to read notes",
+				zap.String("seed", seed),
+				zap.Error(err),
+			)
+			return err
+		}
+		return c.JSON(http.StatusOK, found)
+	})
+
+	e.POST("/notes", func(c echo.Context) error {
+		payload := notes.Request{}
+		if err := c.Bind(&payload); err != nil {
+			log.Log.Error("Failed to read note",
 				zap.Error(err),
 			)
 			return err
 		}
-		old := settings.State
-		settings.State = payload
-		f, err := os.OpenFile("settings.json", os.O_RDWR|os.O_CREATE|os.O_TRUNC, os.ModePerm)
+		if payload.Seed == "" {
+			payload.Seed = tracked().Debug.Seed
+		}
+		// only the seed being tracked has a state to check the scene and check names against
+		if current, ok := stateFor(payload.Seed); ok {
+			if err := notes.Validate(current, payload.Scene, payload.Check); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+		}
+		note, err := notes.Add(payload, time.Now())
+		if errors.Is(err, notes.ErrInvalid) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		} else if err != nil {
+			log.Log.Error("Failed to save note",
+				zap.String("seed", payload.Seed),
+				zap.Error(err),
+			)
+			return err
+		}
+		return c.JSON(http.StatusCreated, note)
+	})
+
+	e.PUT("/notes/:id", func(c echo.Context) error {
+		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
-			log.Log.Error("Failed to open settings file for writing",
+			return echo.NewHTTPError(http.StatusBadRequest, "note id must be a number")
+		}
+		payload := notes.Request{}
+		if err := c.Bind(&payload); err != nil {
+			log.Log.Error("Failed to read note",
+				zap.Error(err),
+			)
+			return err
+		}
+		if payload.Seed == "" {
+			payload.Seed = tracked().Debug.Seed
+		}
+		note, err := notes.Edit(payload.Seed, id, payload, time.Now())
+		if errors.Is(err, notes.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		} else if err != nil {
+			log.Log.Error("Failed to save note",
+				zap.String("seed", payload.Seed),
 				zap.Error(err),
 			)
 			return err
 		}
+		return c.JSON(http.StatusOK, note)
+	})
 
-		q, err := json.MarshalIndent(payload, "", "	")
+	e.DELETE("/notes/:id", func(c echo.Context) error {
+		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
-			log.Log.Error("Failed to marshall settings struct into string",
+			return echo.NewHTTPError(http.StatusBadRequest, "note id must be a number")
+		}
+		seed := c.QueryParam("seed")
+		if seed == "" {
+			seed = tracked().Debug.Seed
+		}
+		err = notes.Delete(seed, id)
+		if errors.Is(err, notes.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		} else if err != nil {
+			log.Log.Error("Failed to delete note",
+				zap.String("seed", seed),
 				zap.Error(err),
 			)
 			return err
 		}
+		return c.NoContent(http.StatusNoContent)
+	})
 
-		_, err = f.Write(q)
+	e.GET("/timeline", func(c echo.Context) error {
+		seed := c.QueryParam("seed")
+		if seed == "" {
+			seed = tracker.State.Get().Debug.Seed
+		}
+		events, err := timeline.Get(seed)
+		if errors.Is(err, os.ErrNotExist) {
+			return echo.NewHTTPError(http.StatusNotFound, "no timeline recorded for seed")
+		} else if err != nil {
+			log.Log.Error("Failed to read timeline",
+				zap.String("seed", seed),
+				zap.Error(err),
+			)
+			return err
+		}
+		return c.JSON(http.StatusOK, events)
+	})
+
+	e.GET("/seeds", func(c echo.Context) error {
+		summaries, err := archive.List()
 		if err != nil {
-			log.Log.Error("Failed to write settings file",
+			log.Log.Error("Failed to list archived seeds",
+				zap.Error(err),
+			)
+			return err
+		}
+		return c.JSON(http.StatusOK, summaries)
+	})
+
+	e.GET("/seeds/:seed", func(c echo.Context) error {
+		entry, err := archive.Get(c.Param("seed"))
+		if errors.Is(err, os.ErrNotExist) {
+			return echo.NewHTTPError(http.StatusNotFound, "seed not archived")
+		} else if err != nil {
+			log.Log.Error("Failed to read archived seed",
+				zap.String("seed", c.Param("seed")),
 				zap.Error(err),
 			)
 			return err
 		}
-		f.Close()
+		return c.JSON(http.StatusOK, entry)
+	})
+
+	e.DELETE("/seeds/:seed", func(c echo.Context) error {
+		err := archive.Delete(c.Param("seed"))
+		if errors.Is(err, os.ErrNotExist) {
+			return echo.NewHTTPError(http.StatusNotFound, "seed not archived")
+		} else if err != nil {
+			log.Log.Error("Failed to delete archived seed",
+				zap.String("seed", c.Param("seed")),
+				zap.Error(err),
+			)
+			return err
+		}
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	e.GET("/events", stream(tracker.State))
+
+	e.GET("/nospoiler/events", stream(tracker.NoSpoilerState))
+
+	e.GET("/health", func(c echo.Context) error {
+		report := health.Check(tracker.State.Get())
+		if !report.Ready {
+			return c.JSON(http.StatusServiceUnavailable, report)
+		}
+		return c.JSON(http.StatusOK, report)
+	})
+
+	e.GET("/metrics", serveMetrics)
 
-		if old.Address != settings.State.Address {
-			log.Log.Warn("Binding address has changed! PLEASE RESTART THIS FOR CHANGES TO TAKE EFFECT")
+	e.GET("/settings", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, settings.State.Get())
+	})
+
+	e.POST("/settings", func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		if err == nil {
+			// check the payload before anything is changed
+			err = json.Unmarshal(body, &settings.Settings{})
+		}
+		if err != nil {
+			log.Log.Error("Failed to read new settings",
+				zap.Error(err),
+			)
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		updated, err := settings.Update(func(current settings.Settings) settings.Settings {
+			// fields left out of the payload keep their current values
+			json.Unmarshal(body, &current)
+			return current
+		})
+		if err != nil {
+			return err
 		}
 
-		return c.JSON(http.StatusOK, settings.State)
+		return c.JSON(http.StatusOK, updated)
 	})
 
-	log.Log.Error("Exiting server", zap.Error(e.Start(settings.State.Address)))
+	log.Log.Error("Exiting server", zap.Error(e.Start(settings.State.Get().Address)))
+}
+
+// tracked returns the state of the seed being played: the spoiler log parse when there is one, otherwise the
+// parse of the save alone, which doesn't need a spoiler log
+func tracked() tracker.Save {
+	if save := tracker.State.Get(); save.Debug.Seed != "" {
+		return save
+	}
+	return tracker.NoSpoilerState.Get()
+}
+
+// stateFor returns the state parsed for seed, preferring the one with the spoiler log since it knows every check
+func stateFor(seed string) (tracker.Save, bool) {
+	if seed == "" {
+		return tracker.Save{}, false
+	}
+	for _, state := range []*store.Store[tracker.Save]{tracker.State, tracker.NoSpoilerState} {
+		if save := state.Get(); save.Debug.Seed == seed {
+			return save, true
+		}
+	}
+	return tracker.Save{}, false
 }
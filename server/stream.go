@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"entrance1/annotations"
+	"entrance1/log"
+	"entrance1/store"
+	"entrance1/tracker"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	maxStreamClients  = 64
+	keepaliveInterval = 15 * time.Second
+)
+
+var (
+	streamClients int32
+)
+
+func writeEvent(res *echo.Response, event string, save tracker.Save) error {
+	return writeData(res, event, annotations.Merge(save))
+}
+
+func writeData(res *echo.Response, event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	res.Flush()
+	return nil
+}
+
+// stream serves a server-sent event stream: a full snapshot on connect, then one update per state change.
+// slow clients never hold up the poller, they just skip straight to the newest state
+func stream(state *store.Store[tracker.Save]) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if atomic.AddInt32(&streamClients, 1) > maxStreamClients {
+			atomic.AddInt32(&streamClients, -1)
+			return echo.NewHTTPError(http.StatusServiceUnavailable, "too many stream clients connected")
+		}
+		defer atomic.AddInt32(&streamClients, -1)
+
+		// subscribe before taking the snapshot so no update can slip between the two
+		sub := state.Subscribe()
+		defer sub.Close()
+
+		res := c.Response()
+		res.Header().Set(echo.HeaderContentType, "text/event-stream")
+		res.Header().Set(echo.HeaderCacheControl, "no-cache")
+		res.Header().Set(echo.HeaderConnection, "keep-alive")
+		res.WriteHeader(http.StatusOK)
+
+		snapshot := state.Get()
+		if err := writeEvent(res, "snapshot", snapshot); err != nil {
+			return nil
+		}
+		mismatched := snapshot.Mismatch.Active
+
+		keepalive := time.NewTicker(keepaliveInterval)
+		defer keepalive.Stop()
+
+		for {
+			select {
+			case <-c.Request().Context().Done():
+				return nil
+			case save := <-sub.C:
+				if err := writeEvent(res, "update", save); err != nil {
+					log.Log.Debug("Dropping event stream client",
+						zap.String("remote", c.RealIP()),
+						zap.Error(err),
+					)
+					return nil
+				}
+				// runners need to know the moment the spoiler stops describing their save, and when it's fixed
+				if save.Mismatch.Active != mismatched {
+					mismatched = save.Mismatch.Active
+					if err := writeData(res, "mismatch", save.Mismatch); err != nil {
+						return nil
+					}
+				}
+			case <-keepalive.C:
+				// comment lines keep idle proxies from closing the connection
+				if _, err := fmt.Fprint(res, ": keepalive\n\n"); err != nil {
+					return nil
+				}
+				res.Flush()
+			}
+		}
+	}
+}
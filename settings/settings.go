@@ -3,8 +3,11 @@ package settings
 import (
 	"encoding/json"
 	"entrance1/log"
+	"entrance1/store"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"sync"
 
 	"go.uber.org/zap"
 )
@@ -13,29 +16,140 @@ type (
 	Settings struct {
 		SecretLegend string `json:"secretLegend"`
 		Address      string `json:"address"`
+		// PinnedSave is the name of the save file to track. when empty, the most recently written save is used
+		PinnedSave string `json:"pinnedSave"`
+		// MapLayout is a json file of scene coordinates for the rendered map. defaults to layout.json
+		MapLayout string `json:"mapLayout"`
+		// MismatchPolicy picks what to serve when the save and spoiler log seeds differ: saveonly (the default),
+		// withhold or serve
+		MismatchPolicy string `json:"mismatchPolicy"`
 	}
 )
 
 var (
-	State Settings
+	// State is the settings file with any overrides applied on top
+	State = store.New(Settings{})
+	// Path is the settings file read by Load and written by Update
+	Path = "settings.json"
+	// Defaults is written out when there is no settings file yet
+	Defaults = Settings{
+		Address: ":8000",
+	}
+
+	// mu guards saved and overrides, and keeps writes to the settings file in order
+	mu sync.Mutex
+	// saved is what the settings file holds
+	saved     Settings
+	overrides Settings
 )
 
+// effective applies the overrides on top of the settings file, mu must be held
+func effective() Settings {
+	current := saved
+	if overrides.Address != "" {
+		current.Address = overrides.Address
+	}
+	if overrides.SecretLegend != "" {
+		current.SecretLegend = overrides.SecretLegend
+	}
+	return current
+}
+
+// Override replaces the settings file's address and secret legend with those set in o. overrides only live in
+// memory, Update never writes them to the settings file
+func Override(o Settings) {
+	mu.Lock()
+	defer mu.Unlock()
+	overrides = Settings{
+		Address:      o.Address,
+		SecretLegend: o.SecretLegend,
+	}
+	State.Set(effective())
+}
+
+// Read decodes the settings file at path without making it the active settings
+func Read(path string) (Settings, error) {
+	var settings Settings
+	s, err := os.Open(path)
+	if err != nil {
+		return Settings{}, err
+	}
+	defer s.Close()
+	if err := json.NewDecoder(s).Decode(&settings); err != nil {
+		return Settings{}, fmt.Errorf("Failed to decode %s: %w", path, err)
+	}
+	return settings, nil
+}
+
 func Load() {
+	mu.Lock()
+	defer mu.Unlock()
 	// no longer assume there's a settings.json
 	var settings Settings
-	s, err := os.Open("settings.json")
+	s, err := os.Open(Path)
 	if err != nil {
-		standard := Settings{
-			Address: ":8000",
-		}
+		standard := Defaults
 		q, _ := json.MarshalIndent(standard, "", "	")
-		ioutil.WriteFile("settings.json", q, os.ModePerm)
+		ioutil.WriteFile(Path, q, os.ModePerm)
 		log.Log.Warn("No valid settings found! Running on default listener -- this can be configured via API or settings.json",
 			zap.String("listener", standard.Address),
 		)
-		State = standard
+		saved = standard
 	} else {
 		json.NewDecoder(s).Decode(&settings)
-		State = settings
+		s.Close()
+		saved = settings
+	}
+	State.Set(effective())
+}
+
+// Update changes the settings with fn, makes the result the active settings and writes it to the settings file.
+// no other update runs until fn has returned, so nothing is lost between reading and writing. fn is given the
+// settings as the file holds them, without overrides, and the active settings are returned with them. a field
+// set to the value it is overridden with keeps what the file holds, so posting back the active settings doesn't
+// write the overrides to the file
+func Update(fn func(Settings) Settings) (Settings, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	old := State.Get()
+	updated := fn(saved)
+	if overrides.Address != "" && updated.Address == overrides.Address {
+		updated.Address = saved.Address
+	}
+	if overrides.SecretLegend != "" && updated.SecretLegend == overrides.SecretLegend {
+		updated.SecretLegend = saved.SecretLegend
+	}
+	saved = updated
+	current := effective()
+	State.Set(current)
+
+	f, err := os.OpenFile(Path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		log.Log.Error("Failed to open settings file for writing",
+			zap.Error(err),
+		)
+		return current, err
+	}
+	defer f.Close()
+
+	q, err := json.MarshalIndent(saved, "", "	")
+	if err != nil {
+		log.Log.Error("Failed to marshall settings struct into string",
+			zap.Error(err),
+		)
+		return current, err
+	}
+
+	_, err = f.Write(q)
+	if err != nil {
+		log.Log.Error("Failed to write settings file",
+			zap.Error(err),
+		)
+		return current, err
+	}
+
+	if old.Address != current.Address {
+		log.Log.Warn("Binding address has changed! PLEASE RESTART THIS FOR CHANGES TO TAKE EFFECT")
 	}
+	return current, nil
 }
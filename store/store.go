@@ -0,0 +1,99 @@
+package store
+
+import (
+	"sync"
+)
+
+type (
+	// Store holds the latest snapshot of a value that is written by one goroutine and read by many.
+	// snapshots are handed out by value and must be treated as read-only: writers always build a
+	// fresh value and Set it rather than mutating the one they got from Get
+	Store[T any] struct {
+		mu          sync.RWMutex
+		value       T
+		subscribers map[*Subscription[T]]struct{}
+	}
+	// Subscription receives every new snapshot published to a store. its channel only ever holds the
+	// newest snapshot, so a slow reader skips intermediate values instead of blocking the writer
+	Subscription[T any] struct {
+		C <-chan T
+
+		updates chan T
+		store   *Store[T]
+	}
+)
+
+func New[T any](initial T) *Store[T] {
+	return &Store[T]{
+		value:       initial,
+		subscribers: map[*Subscription[T]]struct{}{},
+	}
+}
+
+// Get returns the current snapshot
+func (s *Store[T]) Get() T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.value
+}
+
+// Set replaces the current snapshot and notifies every subscriber
+func (s *Store[T]) Set(value T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.value = value
+	s.notify()
+}
+
+// Update atomically replaces the current snapshot with the result of fn, and returns the new snapshot
+func (s *Store[T]) Update(fn func(T) T) T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.value = fn(s.value)
+	s.notify()
+	return s.value
+}
+
+// notify must be called with the write lock held
+func (s *Store[T]) notify() {
+	for sub := range s.subscribers {
+		select {
+		case sub.updates <- s.value:
+		default:
+			// the subscriber hasn't picked up the last snapshot yet, so replace it with the newer one.
+			// only writers send, and they hold the lock, so there is always room after draining
+			select {
+			case <-sub.updates:
+			default:
+			}
+			sub.updates <- s.value
+		}
+	}
+}
+
+// Subscribe registers for future snapshots. Close must be called once the subscription is no longer read
+func (s *Store[T]) Subscribe() *Subscription[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	updates := make(chan T, 1)
+	sub := &Subscription[T]{
+		C:       updates,
+		updates: updates,
+		store:   s,
+	}
+	s.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Subscribers returns how many subscriptions are currently open
+func (s *Store[T]) Subscribers() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.subscribers)
+}
+
+func (sub *Subscription[T]) Close() {
+	sub.store.mu.Lock()
+	defer sub.store.mu.Unlock()
+	delete(sub.store.subscribers, sub)
+}
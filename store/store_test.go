@@ -0,0 +1,138 @@
+package store
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetSet(t *testing.T) {
+	s := New(1)
+	if got := s.Get(); got != 1 {
+		t.Fatalf("Get() = %d, want 1", got)
+	}
+	s.Set(2)
+	if got := s.Get(); got != 2 {
+		t.Fatalf("Get() after Set(2) = %d, want 2", got)
+	}
+	if got := s.Update(func(v int) int { return v * 10 }); got != 20 {
+		t.Fatalf("Update() = %d, want 20", got)
+	}
+	if got := s.Get(); got != 20 {
+		t.Fatalf("Get() after Update = %d, want 20", got)
+	}
+}
+
+func TestSubscribeReceives(t *testing.T) {
+	s := New("")
+	sub := s.Subscribe()
+	defer sub.Close()
+
+	s.Set("first")
+	select {
+	case got := <-sub.C:
+		if got != "first" {
+			t.Fatalf("received %q, want %q", got, "first")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no value received after Set")
+	}
+}
+
+// a subscriber that doesn't keep up only ever sees the newest value, and never blocks the writer
+func TestSlowSubscriberGetsNewest(t *testing.T) {
+	s := New(0)
+	sub := s.Subscribe()
+	defer sub.Close()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 1; i <= 100; i++ {
+			s.Set(i)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Set blocked on a subscriber that wasn't reading")
+	}
+
+	if got := <-sub.C; got != 100 {
+		t.Fatalf("received %d, want the newest value 100", got)
+	}
+	select {
+	case got := <-sub.C:
+		t.Fatalf("received %d, want the dropped values to stay dropped", got)
+	default:
+	}
+}
+
+func TestClose(t *testing.T) {
+	s := New(0)
+	sub := s.Subscribe()
+	if got := s.Subscribers(); got != 1 {
+		t.Fatalf("Subscribers() = %d, want 1", got)
+	}
+	sub.Close()
+	if got := s.Subscribers(); got != 0 {
+		t.Fatalf("Subscribers() after Close = %d, want 0", got)
+	}
+	s.Set(1)
+	select {
+	case got := <-sub.C:
+		t.Fatalf("received %d after Close", got)
+	default:
+	}
+}
+
+// run with -race: readers, writers and subscribers coming and going all at once
+func TestConcurrentAccess(t *testing.T) {
+	s := New(map[string]int{"count": 0})
+	const workers = 8
+	const rounds = 200
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < rounds; j++ {
+				_ = s.Get()["count"]
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < rounds; j++ {
+				s.Set(map[string]int{"count": -i})
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < rounds; j++ {
+				// snapshots are read-only, so build a fresh map
+				s.Update(func(current map[string]int) map[string]int {
+					return map[string]int{"count": current["count"] + 1}
+				})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < rounds/10; j++ {
+				sub := s.Subscribe()
+				select {
+				case value := <-sub.C:
+					_ = value["count"]
+				default:
+				}
+				s.Subscribers()
+				sub.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := s.Subscribers(); got != 0 {
+		t.Fatalf("Subscribers() = %d after every subscription was closed, want 0", got)
+	}
+}
@@ -0,0 +1,212 @@
+package timeline
+
+import (
+	"entrance1/log"
+	"entrance1/persist"
+	"entrance1/store"
+	"entrance1/tracker"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type (
+	Event struct {
+		Time   time.Time
+		Kind   string
+		Scene  string
+		Name   string
+		Detail string
+	}
+	Timeline struct {
+		Seed    string
+		Started time.Time
+		Events  []Event
+		// Known remembers everything already recorded, so a restart doesn't replay old discoveries
+		Known map[string]bool
+	}
+)
+
+const (
+	KindStarted = "started"
+	KindDoor    = "door"
+	KindCheck   = "check"
+	KindCode    = "code"
+
+	Dir = "timelines"
+)
+
+var (
+	mu      sync.Mutex
+	current *Timeline
+)
+
+// discoveries lists everything found in a save, keyed so the same discovery always has the same key
+func discoveries(save tracker.Save) map[string]Event {
+	found := map[string]Event{}
+	for scene, data := range save.Scenes {
+		for door, destination := range data.Entrances {
+			if destination.Scene == "" || destination.Scene == tracker.UnknownScene {
+				continue
+			}
+			found["door:"+door] = Event{
+				Kind:   KindDoor,
+				Scene:  scene,
+				Name:   door,
+				Detail: destination.Scene + " - " + destination.Door,
+			}
+		}
+		for check, ok := range data.Checks {
+			if !ok {
+				continue
+			}
+			found["check:"+scene+":"+check] = Event{
+				Kind:  KindCheck,
+				Scene: scene,
+				Name:  check,
+			}
+		}
+	}
+	for family, codes := range save.Codes {
+		for code, ok := range codes {
+			if !ok {
+				continue
+			}
+			found["code:"+family+":"+code] = Event{
+				Kind:  KindCode,
+				Scene: family,
+				Name:  code,
+			}
+		}
+	}
+	return found
+}
+
+// sortEvents keeps events that share a timestamp in a stable, readable order
+func sortEvents(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].Time.Equal(events[j].Time) {
+			return events[i].Time.Before(events[j].Time)
+		}
+		if events[i].Kind != events[j].Kind {
+			return events[i].Kind < events[j].Kind
+		}
+		if events[i].Scene != events[j].Scene {
+			return events[i].Scene < events[j].Scene
+		}
+		return events[i].Name < events[j].Name
+	})
+}
+
+// Diff lists everything discovered in next that wasn't already discovered in prev
+func Diff(prev, next tracker.Save) []Event {
+	before := discoveries(prev)
+	events := []Event{}
+	for key, event := range discoveries(next) {
+		if _, ok := before[key]; !ok {
+			events = append(events, event)
+		}
+	}
+	sortEvents(events)
+	return events
+}
+
+func load(seed string) (*Timeline, error) {
+	t := &Timeline{}
+	if err := persist.Load(persist.SeedFile(Dir, seed), t); err != nil {
+		return nil, err
+	}
+	if t.Known == nil {
+		t.Known = map[string]bool{}
+	}
+	return t, nil
+}
+
+// Record appends every new discovery in save to the timeline of its seed
+func Record(save tracker.Save, now time.Time) {
+	seed := save.Debug.Seed
+	if seed == "" {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// swap in the timeline for this seed if we changed save slots
+	if current == nil || current.Seed != seed {
+		t, err := load(seed)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				log.Log.Error("Failed to load timeline, starting a new one",
+					zap.String("seed", seed),
+					zap.Error(err),
+				)
+			}
+			t = nil
+		}
+		current = t
+	}
+
+	found := discoveries(save)
+	if current == nil {
+		// the first time we see a seed we can't know when earlier discoveries happened, so only mark the start
+		current = &Timeline{
+			Seed:    seed,
+			Started: now,
+			Events:  []Event{{Time: now, Kind: KindStarted}},
+			Known:   map[string]bool{},
+		}
+		for key := range found {
+			current.Known[key] = true
+		}
+	} else {
+		added := []Event{}
+		for key, event := range found {
+			if current.Known[key] {
+				continue
+			}
+			current.Known[key] = true
+			event.Time = now
+			added = append(added, event)
+		}
+		if len(added) == 0 {
+			return
+		}
+		sortEvents(added)
+		current.Events = append(current.Events, added...)
+	}
+
+	if err := persist.Save(persist.SeedFile(Dir, seed), current); err != nil {
+		log.Log.Error("Failed to save timeline",
+			zap.String("seed", seed),
+			zap.Error(err),
+		)
+	}
+}
+
+// Get returns the recorded events for a seed
+func Get(seed string) ([]Event, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if current != nil && current.Seed == seed {
+		return append([]Event{}, current.Events...), nil
+	}
+	t, err := load(seed)
+	if err != nil {
+		return nil, err
+	}
+	return t.Events, nil
+}
+
+// Watch records every snapshot published to state until the process exits
+func Watch(state *store.Store[tracker.Save]) {
+	sub := state.Subscribe()
+	defer sub.Close()
+	for save := range sub.C {
+		Record(save, time.Now())
+	}
+}
@@ -0,0 +1,93 @@
+package tracker
+
+import "fmt"
+
+// where a door's destination came from, see Door.Source
+const (
+	SourceSpoiler = "spoiler"
+	SourceSave    = "save"
+	SourceManual  = "manual"
+)
+
+// DoorAnnotation is what the player has told us about a door by hand
+type DoorAnnotation struct {
+	// Link is the door on the other side, as named in DataPack.Regions
+	Link    string `json:",omitempty"`
+	DeadEnd bool   `json:",omitempty"`
+	Note    string `json:",omitempty"`
+}
+
+// GetDoorRegion returns the region a door leads out of
+func (p *DataPack) GetDoorRegion(door string) (string, error) {
+	region, ok := p.Regions[door]
+	if !ok {
+		return "", fmt.Errorf("unrecognized door: %s", door)
+	}
+	return region, nil
+}
+
+// Annotate merges manual door annotations into save. a link only fills in a door whose destination isn't
+// known yet, so the spoiler log always wins over memory. notes and dead ends are attached to any door.
+// save is a shared snapshot, so every scene that changes is copied first
+func Annotate(save Save, doors map[string]DoorAnnotation) Save {
+	if len(doors) == 0 {
+		return save
+	}
+
+	scenes := make(map[string]Scene, len(save.Scenes))
+	for name, scene := range save.Scenes {
+		scenes[name] = scene
+	}
+	save.Scenes = scenes
+	copied := map[string]bool{}
+	pack := PackFor(save)
+	regions := pack.Regions
+
+	for door, annotation := range doors {
+		region, ok := regions[door]
+		if !ok {
+			continue
+		}
+		scene, ok := scenes[region]
+		if !ok {
+			continue
+		}
+		destination, ok := scene.Entrances[door]
+		if !ok {
+			continue
+		}
+		if !copied[region] {
+			entrances := make(map[string]Door, len(scene.Entrances))
+			for name, door := range scene.Entrances {
+				entrances[name] = door
+			}
+			scene.Entrances = entrances
+			copied[region] = true
+		}
+
+		if linked, ok := regions[annotation.Link]; ok && (destination.Scene == "" || destination.Scene == UnknownScene) {
+			if destination.Scene == "" {
+				scene.Totals.Entrances.Undiscovered--
+				save.Totals.Entrances.Undiscovered--
+			}
+			destination.Scene = linked
+			destination.Door = annotation.Link
+			destination.Source = SourceManual
+		}
+		if annotation.DeadEnd {
+			destination.DeadEnd = true
+			if destination.Source == "" {
+				destination.Source = SourceManual
+			}
+		}
+		if annotation.Note != "" {
+			destination.Note = annotation.Note
+		}
+		scene.Entrances[door] = destination
+		scenes[region] = scene
+	}
+
+	// manual links can open up new scenes, and dead ends aren't worth suggesting
+	applyLogic(&save, pack)
+	return save
+}
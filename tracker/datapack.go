@@ -0,0 +1,207 @@
+package tracker
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DataPack holds the scene, door, code, logic and progression tables for a range of randomizer versions, so a
+// randomizer update only needs a new json file instead of a new build
+type DataPack struct {
+	// Version identifies the pack itself, and is reported in Debug.DataPack
+	Version string `json:"version"`
+	// Randomizer is the oldest randomizer version the pack is meant for
+	Randomizer string `json:"randomizer"`
+	// Scenes translates the game's scene names into the names used everywhere else
+	Scenes map[string]string `json:"scenes"`
+	// Doors lists the doors leading out of each scene
+	Doors map[string][]string `json:"doors"`
+	// Regions is the scene each door leads out of, keyed by the door names used in the spoiler log
+	Regions map[string]string `json:"regions"`
+	// Codes maps the holy cross codes and fairies of each scene to the save flag set once they're solved
+	Codes map[string]map[string]string `json:"codes"`
+	// Requirements lists what is needed to go through a door from the scene it belongs to
+	Requirements map[string]Requirement `json:"requirements"`
+	// CheckKeywords gives requirements to checks by name, since check names come from the spoiler log
+	CheckKeywords map[string]Requirement `json:"checkKeywords"`
+	// Progression names the items and flags behind the curated progression view
+	Progression ProgressionItems `json:"progression"`
+}
+
+// DataDir is the directory next to the binary that packs are read from on top of the embedded ones
+const DataDir = "data"
+
+//go:embed data/*.json
+var embeddedPacks embed.FS
+
+var (
+	packsMu sync.Mutex
+	// packs is every known pack, oldest randomizer first
+	packs = mustEmbeddedPacks()
+)
+
+func readPack(fsys fs.FS, name string) (*DataPack, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	pack := &DataPack{}
+	if err := json.Unmarshal(data, pack); err != nil {
+		return nil, fmt.Errorf("Failed to decode data pack %s: %w", name, err)
+	}
+	if pack.Version == "" {
+		return nil, fmt.Errorf("data pack %s has no version", name)
+	}
+	return pack, nil
+}
+
+// readPacks reads every json file in dir of fsys
+func readPacks(fsys fs.FS, dir string) ([]*DataPack, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	found := []*DataPack{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		pack, err := readPack(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, pack)
+	}
+	return found, nil
+}
+
+// mustEmbeddedPacks loads the embedded packs, which are all there is until LoadDataPacks adds to them
+func mustEmbeddedPacks() []*DataPack {
+	embedded, err := readPacks(embeddedPacks, "data")
+	if err != nil || len(embedded) == 0 {
+		panic(fmt.Sprintf("embedded data packs are broken: %v", err))
+	}
+	return sortPacks(embedded)
+}
+
+// sortPacks orders packs by the randomizer version they start at, dropping all but the last of any version
+func sortPacks(list []*DataPack) []*DataPack {
+	byVersion := map[string]*DataPack{}
+	for _, pack := range list {
+		byVersion[pack.Version] = pack
+	}
+	sorted := []*DataPack{}
+	for _, pack := range byVersion {
+		sorted = append(sorted, pack)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if c := compareVersions(sorted[i].Randomizer, sorted[j].Randomizer); c != 0 {
+			return c < 0
+		}
+		return sorted[i].Version < sorted[j].Version
+	})
+	return sorted
+}
+
+// compareVersions compares dotted version numbers part by part. parts that aren't numbers compare as text
+func compareVersions(a, b string) int {
+	left := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	right := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(left) || i < len(right); i++ {
+		l, r := "0", "0"
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		ln, lerr := strconv.Atoi(l)
+		rn, rerr := strconv.Atoi(r)
+		switch {
+		case lerr == nil && rerr == nil && ln != rn:
+			if ln < rn {
+				return -1
+			}
+			return 1
+		case (lerr != nil || rerr != nil) && l != r:
+			return strings.Compare(l, r)
+		}
+	}
+	return 0
+}
+
+// PackDir returns the override directory next to the running binary
+func PackDir() string {
+	executable, err := os.Executable()
+	if err != nil {
+		return DataDir
+	}
+	return filepath.Join(filepath.Dir(executable), DataDir)
+}
+
+// LoadDataPacks adds the packs in dir to the embedded ones. a pack with the same version as an embedded one
+// replaces it. a missing directory is not an error
+func LoadDataPacks(dir string) error {
+	overrides, err := readPacks(os.DirFS(dir), ".")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	embedded, err := readPacks(embeddedPacks, "data")
+	if err != nil {
+		return err
+	}
+	packsMu.Lock()
+	defer packsMu.Unlock()
+	packs = sortPacks(append(embedded, overrides...))
+	return nil
+}
+
+// DataPacks returns every known pack, oldest randomizer first
+func DataPacks() []*DataPack {
+	packsMu.Lock()
+	defer packsMu.Unlock()
+	return append([]*DataPack{}, packs...)
+}
+
+// SelectDataPack returns the newest pack meant for the given randomizer version. an unknown version gets the
+// newest pack overall
+func SelectDataPack(randomizer string) *DataPack {
+	packsMu.Lock()
+	defer packsMu.Unlock()
+	if randomizer == "" {
+		return packs[len(packs)-1]
+	}
+	selected := packs[0]
+	for _, pack := range packs {
+		if compareVersions(pack.Randomizer, randomizer) <= 0 {
+			selected = pack
+		}
+	}
+	return selected
+}
+
+// PackFor returns the pack a state was built from, as named by Debug.DataPack, so lookups on a state agree
+// with how it was parsed. a state from a pack that is no longer known gets the newest pack
+func PackFor(save Save) *DataPack {
+	packsMu.Lock()
+	defer packsMu.Unlock()
+	for _, pack := range packs {
+		if pack.Version == save.Debug.DataPack {
+			return pack
+		}
+	}
+	return packs[len(packs)-1]
+}
@@ -0,0 +1,116 @@
+package tracker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// usePacks replaces the known packs for the length of a test
+func usePacks(t *testing.T, list ...*DataPack) {
+	packsMu.Lock()
+	previous := packs
+	packs = sortPacks(list)
+	packsMu.Unlock()
+	t.Cleanup(func() {
+		packsMu.Lock()
+		packs = previous
+		packsMu.Unlock()
+	})
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.2.4", -1},
+		{"1.10", "1.9", 1},
+		{"2", "1.9.9", 1},
+		{"1.2", "1.2.0", 0},
+		{"1.2", "1.2.1", -1},
+		{"v1.2", "1.2", 0},
+		{"v1.10", "v1.2", 1},
+		{"1.2.beta", "1.2.beta", 0},
+		{"1.2.alpha", "1.2.beta", -1},
+		{"1.3.alpha", "1.2.beta", 1},
+		{"1.2.rc1", "1.2.0", 1},
+	}
+	for _, test := range tests {
+		if got := compareVersions(test.a, test.b); got != test.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+		if got := compareVersions(test.b, test.a); got != -test.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", test.b, test.a, got, -test.want)
+		}
+	}
+}
+
+func TestSelectDataPack(t *testing.T) {
+	usePacks(t,
+		&DataPack{Version: "c", Randomizer: "1.10.0"},
+		&DataPack{Version: "a", Randomizer: "0.5"},
+		&DataPack{Version: "b", Randomizer: "1.2"},
+	)
+
+	tests := []struct {
+		randomizer string
+		want       string
+	}{
+		{"", "c"},
+		{"0.1", "a"},
+		{"0.5", "a"},
+		{"1.1.9", "a"},
+		{"1.2", "b"},
+		{"1.9", "b"},
+		{"v1.10", "c"},
+		{"3", "c"},
+	}
+	for _, test := range tests {
+		if got := SelectDataPack(test.randomizer).Version; got != test.want {
+			t.Errorf("SelectDataPack(%q) picked pack %q, want %q", test.randomizer, got, test.want)
+		}
+	}
+}
+
+func TestLoadDataPacks(t *testing.T) {
+	usePacks(t, DataPacks()...)
+	embedded := len(DataPacks())
+
+	dir := t.TempDir()
+	write := func(name, contents string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("1.json", `{"version": "1", "randomizer": "0.0.0", "scenes": {"Test Scene": "Test"}}`)
+	write("2.json", `{"version": "2", "randomizer": "99.0"}`)
+	write("notes.txt", `not a pack`)
+
+	if err := LoadDataPacks(dir); err != nil {
+		t.Fatalf("LoadDataPacks: %v", err)
+	}
+	if got := len(DataPacks()); got != embedded+1 {
+		t.Fatalf("%d packs loaded, want the %d embedded packs plus one new version", got, embedded)
+	}
+	replaced := SelectDataPack("0.0.0")
+	if replaced.Version != "1" || replaced.Scenes["Test Scene"] != "Test" {
+		t.Errorf("pack 1 was not replaced by the override, got %+v", replaced)
+	}
+	if got := SelectDataPack("").Version; got != "2" {
+		t.Errorf("newest pack is %q, want the override's 2", got)
+	}
+
+	if err := LoadDataPacks(filepath.Join(dir, "missing")); err != nil {
+		t.Errorf("a missing directory should not be an error, got %v", err)
+	}
+
+	write("3.json", `{"randomizer": "100.0"}`)
+	if err := LoadDataPacks(dir); err == nil {
+		t.Error("a pack without a version should be an error")
+	}
+	if got := SelectDataPack("").Version; got != "2" {
+		t.Errorf("a failed load changed the packs, newest is now %q", got)
+	}
+}
@@ -0,0 +1,193 @@
+package tracker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type (
+	GraphOptions struct {
+		// GroupShops draws the shop and its portals in their own cluster
+		GroupShops bool
+		// GroupFarShore draws the far shore hub in its own cluster
+		GroupFarShore bool
+	}
+	// graphEdge is a discovered transition, stored once for both directions
+	graphEdge struct {
+		From     string
+		FromDoor string
+		To       string
+		ToDoor   string
+	}
+	// graphStub is a door that hasn't been paired yet
+	graphStub struct {
+		Scene   string
+		Door    string
+		Visited bool
+	}
+	graph struct {
+		scenes []string
+		edges  []graphEdge
+		stubs  []graphStub
+	}
+)
+
+const (
+	GraphDOT     = "dot"
+	GraphMermaid = "mermaid"
+
+	farShore = "Far Shore"
+	shop     = "Shop"
+)
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// collectGraph turns the scenes of a save into nodes, paired doors and dangling doors
+func collectGraph(save Save) graph {
+	g := graph{
+		scenes: sortedKeys(save.Scenes),
+	}
+	// both sides of a transition are listed once they've been walked through, and shop portals are listed on
+	// both the shop and the scene, so only draw each door once. the shared shop door names can't be told apart
+	seen := map[string]bool{}
+	mark := func(door string) {
+		if door != shop && door != "Shop Portal" {
+			seen[door] = true
+		}
+	}
+	for _, scene := range g.scenes {
+		entrances := save.Scenes[scene].Entrances
+		for _, door := range sortedKeys(entrances) {
+			destination := entrances[door]
+			if destination.Scene == "" || destination.Scene == UnknownScene {
+				continue
+			}
+			if seen[door] || seen[destination.Door] {
+				continue
+			}
+			mark(door)
+			mark(destination.Door)
+			g.edges = append(g.edges, graphEdge{scene, door, destination.Scene, destination.Door})
+		}
+	}
+	for _, scene := range g.scenes {
+		entrances := save.Scenes[scene].Entrances
+		for _, door := range sortedKeys(entrances) {
+			destination := entrances[door]
+			if seen[door] || (destination.Scene != "" && destination.Scene != UnknownScene) {
+				continue
+			}
+			g.stubs = append(g.stubs, graphStub{Scene: scene, Door: door, Visited: destination.Scene == UnknownScene})
+		}
+	}
+	return g
+}
+
+// groups returns the clusters to draw, keyed by label, as asked for in opts
+func (opts GraphOptions) groups() map[string]string {
+	groups := map[string]string{}
+	if opts.GroupShops {
+		groups[shop] = "Shops"
+	}
+	if opts.GroupFarShore {
+		groups[farShore] = "Far Shore Hub"
+	}
+	return groups
+}
+
+func edgeLabel(edge graphEdge) string {
+	if edge.ToDoor == "" {
+		return edge.FromDoor
+	}
+	return edge.FromDoor + " / " + edge.ToDoor
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// ExportDOT renders the discovered entrance map as an undirected GraphViz graph
+func ExportDOT(save Save, opts GraphOptions) string {
+	g := collectGraph(save)
+	groups := opts.groups()
+	b := &strings.Builder{}
+
+	b.WriteString("graph entrances {\n")
+	b.WriteString("\tnode [shape=box];\n")
+	for _, scene := range g.scenes {
+		if _, ok := groups[scene]; ok {
+			continue
+		}
+		fmt.Fprintf(b, "\t%s;\n", dotQuote(scene))
+	}
+	for _, scene := range sortedKeys(groups) {
+		fmt.Fprintf(b, "\tsubgraph %s {\n", dotQuote("cluster "+groups[scene]))
+		fmt.Fprintf(b, "\t\tlabel=%s;\n", dotQuote(groups[scene]))
+		fmt.Fprintf(b, "\t\t%s;\n", dotQuote(scene))
+		b.WriteString("\t}\n")
+	}
+	for _, edge := range g.edges {
+		fmt.Fprintf(b, "\t%s -- %s [label=%s];\n", dotQuote(edge.From), dotQuote(edge.To), dotQuote(edgeLabel(edge)))
+	}
+	for i, stub := range g.stubs {
+		id := dotQuote(fmt.Sprintf("stub %d", i))
+		style := "dashed"
+		if stub.Visited {
+			style = "dotted"
+		}
+		fmt.Fprintf(b, "\t%s [shape=point];\n", id)
+		fmt.Fprintf(b, "\t%s -- %s [label=%s, style=%s];\n", dotQuote(stub.Scene), id, dotQuote(stub.Door), style)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
+
+// ExportMermaid renders the discovered entrance map as a mermaid flowchart
+func ExportMermaid(save Save, opts GraphOptions) string {
+	g := collectGraph(save)
+	groups := opts.groups()
+	b := &strings.Builder{}
+
+	// mermaid ids can't hold spaces or punctuation, so number the scenes
+	ids := map[string]string{}
+	for i, scene := range g.scenes {
+		ids[scene] = fmt.Sprintf("scene%d", i)
+	}
+
+	b.WriteString("flowchart LR\n")
+	for _, scene := range g.scenes {
+		if _, ok := groups[scene]; ok {
+			continue
+		}
+		fmt.Fprintf(b, "\t%s[%s]\n", ids[scene], mermaidQuote(scene))
+	}
+	for _, scene := range sortedKeys(groups) {
+		fmt.Fprintf(b, "\tsubgraph group%s[%s]\n", ids[scene], mermaidQuote(groups[scene]))
+		fmt.Fprintf(b, "\t\t%s[%s]\n", ids[scene], mermaidQuote(scene))
+		b.WriteString("\tend\n")
+	}
+	for _, edge := range g.edges {
+		fmt.Fprintf(b, "\t%s ---|%s| %s\n", ids[edge.From], mermaidQuote(edgeLabel(edge)), ids[edge.To])
+	}
+	for i, stub := range g.stubs {
+		link := "-.-"
+		if stub.Visited {
+			link = "-.->"
+		}
+		fmt.Fprintf(b, "\tstub%d(( ))\n", i)
+		fmt.Fprintf(b, "\t%s %s|%s| stub%d\n", ids[stub.Scene], link, mermaidQuote(stub.Door), i)
+	}
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package tracker
+
+import (
+	"strconv"
+)
+
+// Hexagons tracks the win condition of hexagon quest
+type Hexagons struct {
+	Collected int
+	Required  int
+	Remaining int
+	// Scenes counts the unfound gold hexagons left in each scene, when the spoiler log reveals them
+	Scenes map[string]int
+}
+
+const (
+	hexagonInventory = "Hexagon Gold"
+	hexagonItem      = "Gold Questagon"
+	hexagonGoalFlag  = "randomizer hexagon quest goal"
+)
+
+// applyHexagons fills in hexagon quest progress from the save, and from the spoiler log when there is one
+func applyHexagons(payload *Save, spoiler *SpoilerLog, save SaveFile) {
+	if !save.HexQuest {
+		return
+	}
+	hexagons := Hexagons{
+		Collected: save.Inventory[hexagonInventory],
+		Scenes:    map[string]int{},
+	}
+	if goal, err := strconv.Atoi(save.Flags[hexagonGoalFlag]); err == nil {
+		hexagons.Required = goal
+	} else if spoiler != nil {
+		hexagons.Required = spoiler.HexagonGoal
+	}
+
+	if spoiler != nil {
+		for _, placement := range spoiler.Placements {
+			if placement.Found || newItem(placement, save.Archipelago).Name != hexagonItem {
+				continue
+			}
+			hexagons.Scenes[placement.Scene]++
+		}
+	}
+
+	hexagons.Remaining = hexagons.Required - hexagons.Collected
+	if hexagons.Remaining < 0 {
+		hexagons.Remaining = 0
+	}
+	payload.Hexagons = &hexagons
+}
@@ -0,0 +1,66 @@
+package tracker
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	Item struct {
+		Name   string
+		Player string `json:",omitempty"`
+		Found  bool
+	}
+	MajorItem struct {
+		Item
+		Scene string
+		Check string
+	}
+)
+
+const (
+	// RevealNone only reports which checks have been found
+	RevealNone = "none"
+	// RevealFound also names the items in checks that have already been found
+	RevealFound = "found"
+	// RevealAll names every item, found or not
+	RevealAll = "all"
+)
+
+// newItem splits an archipelago item into the player it is for and its name, written as "Player's Item"
+func newItem(placement Placement, archipelago bool) Item {
+	item := Item{
+		Name:  placement.Item,
+		Found: placement.Found,
+	}
+	if archipelago {
+		if player, name, ok := strings.Cut(placement.Item, "'s "); ok {
+			item.Player = player
+			item.Name = name
+		}
+	}
+	return item
+}
+
+// CheckItems lists every check by scene, naming the items placed there only as far as reveal allows
+func CheckItems(save Save, reveal string) (map[string]map[string]Item, error) {
+	if reveal != RevealNone && reveal != RevealFound && reveal != RevealAll {
+		return nil, fmt.Errorf("unrecognized reveal mode: %s", reveal)
+	}
+	checks := map[string]map[string]Item{}
+	for scene, data := range save.Scenes {
+		if len(data.Checks) == 0 {
+			continue
+		}
+		checks[scene] = map[string]Item{}
+		for check, found := range data.Checks {
+			item := data.Items[check]
+			if reveal == RevealNone || (reveal == RevealFound && !found) {
+				item = Item{}
+			}
+			item.Found = found
+			checks[scene][check] = item
+		}
+	}
+	return checks, nil
+}
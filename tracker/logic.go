@@ -0,0 +1,134 @@
+package tracker
+
+import (
+	"strings"
+)
+
+type (
+	// Logic marks every undiscovered door and unfound check in a scene as reachable (true) or not (false)
+	Logic struct {
+		Entrances map[string]bool
+		Checks    map[string]bool
+	}
+	Requirement struct {
+		Laurels   bool `json:"laurels,omitempty"`
+		HolyCross bool `json:"holyCross,omitempty"`
+		// Code names the family and entry in DataPack.Codes of a holy cross door. once it has been solved the
+		// door stays open, so it is passable even without knowing holy cross
+		Code [2]string `json:"code"`
+	}
+	// inventory is what the logic cares about, pulled out of the save
+	inventory struct {
+		laurels   bool
+		holyCross bool
+		codes     map[string]map[string]bool
+	}
+)
+
+const logicStart = "Overworld"
+
+func newInventory(save Save) inventory {
+	return inventory{
+		laurels:   save.Current.HasLaurels,
+		holyCross: save.Progression.Pages["Holy Cross"],
+		codes:     save.Codes,
+	}
+}
+
+func (r Requirement) met(inv inventory) bool {
+	if r.Laurels && !inv.laurels {
+		return false
+	}
+	if r.HolyCross && !inv.holyCross {
+		if r.Code[0] == "" || !inv.codes[r.Code[0]][r.Code[1]] {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *DataPack) doorOpen(door string, inv inventory) bool {
+	requirement, ok := p.Requirements[door]
+	return !ok || requirement.met(inv)
+}
+
+func (p *DataPack) checkOpen(check string, inv inventory) bool {
+	for keyword, requirement := range p.CheckKeywords {
+		if strings.Contains(check, keyword) && !requirement.met(inv) {
+			return false
+		}
+	}
+	return true
+}
+
+// reachableScenes walks every discovered transition that can currently be used, starting from spawn, the
+// player's current scene, their respawn point and the dath stone campfire. scenes are treated as fully
+// connected inside, only the doors themselves have requirements
+func reachableScenes(save Save, pack *DataPack, inv inventory) map[string]bool {
+	// transitions work both ways, but each direction needs its own door to be usable
+	edges := map[string][]string{}
+	for scene, data := range save.Scenes {
+		for door, destination := range data.Entrances {
+			if destination.Scene == "" || destination.Scene == UnknownScene {
+				continue
+			}
+			if pack.doorOpen(door, inv) {
+				edges[scene] = append(edges[scene], destination.Scene)
+			}
+			if destination.Door != "" && pack.doorOpen(destination.Door, inv) {
+				edges[destination.Scene] = append(edges[destination.Scene], scene)
+			}
+		}
+	}
+
+	reachable := map[string]bool{}
+	queue := []string{}
+	visit := func(scene string) {
+		if scene == "" || reachable[scene] {
+			return
+		}
+		reachable[scene] = true
+		queue = append(queue, scene)
+	}
+	visit(logicStart)
+	visit(save.Current.Scene)
+	visit(save.Current.Respawn)
+	if save.Current.HasDath {
+		visit(save.Current.Dath)
+	}
+	for len(queue) > 0 {
+		scene := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[scene] {
+			visit(next)
+		}
+	}
+	return reachable
+}
+
+// applyLogic fills in Scene.Logic for every undiscovered door and unfound check
+func applyLogic(payload *Save, pack *DataPack) {
+	inv := newInventory(*payload)
+	reachable := reachableScenes(*payload, pack, inv)
+
+	for name, scene := range payload.Scenes {
+		logic := Logic{
+			Entrances: map[string]bool{},
+			Checks:    map[string]bool{},
+		}
+		for door, destination := range scene.Entrances {
+			if destination.Scene != "" || destination.DeadEnd {
+				continue
+			}
+			logic.Entrances[door] = reachable[name] && pack.doorOpen(door, inv)
+		}
+		for check, found := range scene.Checks {
+			if found {
+				continue
+			}
+			logic.Checks[check] = reachable[name] && pack.checkOpen(check, inv)
+		}
+		scene.Logic = logic
+		payload.Scenes[name] = scene
+	}
+}
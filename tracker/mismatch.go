@@ -0,0 +1,68 @@
+package tracker
+
+// what to serve when the save and the spoiler log come from different seeds
+const (
+	// MismatchSaveOnly serves what the save alone can tell, as if there were no spoiler log. the default
+	MismatchSaveOnly = "saveonly"
+	// MismatchWithhold serves the save's position, items, codes and hexagons, but no scenes
+	MismatchWithhold = "withhold"
+	// MismatchServe serves the spoiler log's pairings and items anyway
+	MismatchServe = "serve"
+)
+
+// Mismatch says whether the save and the spoiler log disagree on the seed, and what was done about it
+type Mismatch struct {
+	Active      bool
+	Policy      string
+	SaveSeed    string
+	SpoilerSeed string
+}
+
+// MismatchPolicy returns policy, or the default when it isn't one of the known policies
+func MismatchPolicy(policy string) string {
+	switch policy {
+	case MismatchWithhold, MismatchServe:
+		return policy
+	}
+	return MismatchSaveOnly
+}
+
+// ApplyMismatch fills in payload.Mismatch and, when the seeds disagree, replaces whatever came from the spoiler
+// log according to policy. payload must have been combined from save and a spoiler log
+func ApplyMismatch(payload Save, save SaveFile, policy string) Save {
+	policy = MismatchPolicy(policy)
+	mismatch := Mismatch{
+		// a spoiler log without a seed can't be told apart from the right one, so it isn't a mismatch
+		Active:      payload.Debug.SpoilerSeed != "" && payload.Debug.Seed != payload.Debug.SpoilerSeed,
+		Policy:      policy,
+		SaveSeed:    payload.Debug.Seed,
+		SpoilerSeed: payload.Debug.SpoilerSeed,
+	}
+	if !mismatch.Active || policy == MismatchServe {
+		payload.Mismatch = mismatch
+		return payload
+	}
+
+	var replacement Save
+	switch policy {
+	case MismatchSaveOnly:
+		replacement, _ = Combine(nil, save)
+	case MismatchWithhold:
+		warnings := []Warning{}
+		pack := SelectDataPack(save.RandomizerVersion)
+		replacement = newSave(pack)
+		applySaveFile(&replacement, pack, save, &warnings)
+		applyProgression(&replacement, pack, save)
+		applyHexagons(&replacement, nil, save)
+		replacement.Debug.RandomizerVersion = save.RandomizerVersion
+		replacement.Debug.DataPack = pack.Version
+	}
+	// keep the spoiler seed around so the mismatch can still be seen, but the replacement was built from the
+	// save alone, so the randomizer version and data pack are the save's
+	debug := payload.Debug
+	debug.RandomizerVersion = replacement.Debug.RandomizerVersion
+	debug.DataPack = replacement.Debug.DataPack
+	replacement.Debug = debug
+	replacement.Mismatch = mismatch
+	return replacement
+}
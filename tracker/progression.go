@@ -0,0 +1,82 @@
+package tracker
+
+import (
+	"strings"
+)
+
+// Progression is a curated view of the inventory, covering the items a layout would show in an item grid
+type Progression struct {
+	// SwordLevel is 0 without a weapon, 1 for the stick and 2 and up for each sword upgrade
+	SwordLevel int
+	Magic      map[string]bool
+	Pages      map[string]bool
+	Keys       map[string]int
+	Fuses      int
+	Bells      map[string]bool
+	Bombs      map[string]bool
+}
+
+// ProgressionItems names what the progression view is built from. display names are the keys of the maps in
+// Progression
+type ProgressionItems struct {
+	// Swords maps the inventory name of each weapon to the sword level it represents
+	Swords map[string]int `json:"swords"`
+	// Magic maps display names to inventory names
+	Magic map[string]string `json:"magic"`
+	// Pages maps display names to the item that unlocks them
+	Pages map[string]string `json:"pages"`
+	// Keys maps display names to inventory names
+	Keys map[string]string `json:"keys"`
+	Fuse string            `json:"fuse"`
+	// Bells maps display names to the save flag set once the bell is rung
+	Bells map[string]string `json:"bells"`
+	// Bombs are granted once enough of them have been found, and live in the global code family
+	Bombs []string `json:"bombs"`
+}
+
+// applyProgression fills in the full inventory and the curated progression view
+func applyProgression(payload *Save, pack *DataPack, save SaveFile) {
+	items := pack.Progression
+	payload.Inventory = map[string]int{}
+	for item, quantity := range save.Inventory {
+		payload.Inventory[item] = quantity
+	}
+	payload.Current.HasDath = save.Inventory["Dath Stone"] > 0
+	payload.Current.HasLaurels = save.Inventory["Hyperdash"] > 0
+
+	progression := Progression{
+		Magic: map[string]bool{},
+		Pages: map[string]bool{},
+		Keys:  map[string]int{},
+		Bells: map[string]bool{},
+		Bombs: map[string]bool{},
+	}
+	for item, level := range items.Swords {
+		if save.Inventory[item] > 0 && level > progression.SwordLevel {
+			progression.SwordLevel = level
+		}
+	}
+	for name, item := range items.Magic {
+		progression.Magic[name] = save.Inventory[item] > 0
+	}
+	for name, item := range items.Pages {
+		progression.Pages[name] = save.Inventory[item] > 0
+		// the randomizer doesn't always leave pages in the inventory, so fall back on the spoiler
+		for _, major := range payload.MajorItems {
+			if major.Found && strings.Contains(major.Name, item) {
+				progression.Pages[name] = true
+			}
+		}
+	}
+	for name, item := range items.Keys {
+		progression.Keys[name] = save.Inventory[item]
+	}
+	progression.Fuses = save.Inventory[items.Fuse]
+	for name, flag := range items.Bells {
+		progression.Bells[name] = save.Flags[flag] == "1"
+	}
+	for _, bomb := range items.Bombs {
+		progression.Bombs[bomb] = payload.Codes[globalCodes][bomb]
+	}
+	payload.Progression = progression
+}
@@ -0,0 +1,215 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Point is the center of a scene on the rendered map, in svg units
+type Point struct {
+	X float64
+	Y float64
+}
+
+const (
+	mapWidth    = 1600.0
+	mapHeight   = 1200.0
+	nodeWidth   = 150.0
+	nodeHeight  = 44.0
+	mapMargin   = nodeWidth / 2
+	layoutSteps = 300
+	// free scenes are snapped to a grid of this size after the force layout, so no two boxes overlap
+	cellWidth  = nodeWidth + 20
+	cellHeight = nodeHeight + 26
+)
+
+// SceneNames returns every scene name in the pack, sorted
+func (p *DataPack) SceneNames() []string {
+	seen := map[string]bool{}
+	regions := []string{}
+	for _, name := range p.Scenes {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		regions = append(regions, name)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// LoadLayout reads scene coordinates from a json file mapping scene names to points
+func LoadLayout(path string) (map[string]Point, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	layout := map[string]Point{}
+	if err := json.NewDecoder(f).Decode(&layout); err != nil {
+		return nil, fmt.Errorf("Failed to decode layout file: %w", err)
+	}
+	return layout, nil
+}
+
+// forceLayout places every region missing from fixed with a deterministic force-directed layout,
+// pulling connected scenes together and pushing everything else apart. fixed regions never move
+func forceLayout(regions []string, edges []graphEdge, fixed map[string]Point) map[string]Point {
+	positions := map[string]Point{}
+	free := []string{}
+	for i, region := range regions {
+		if point, ok := fixed[region]; ok {
+			positions[region] = point
+			continue
+		}
+		// start everything on a circle so the result is the same every time
+		angle := 2 * math.Pi * float64(i) / float64(len(regions))
+		positions[region] = Point{
+			X: mapWidth/2 + math.Cos(angle)*mapWidth/3,
+			Y: mapHeight/2 + math.Sin(angle)*mapHeight/3,
+		}
+		free = append(free, region)
+	}
+	if len(free) == 0 {
+		return positions
+	}
+
+	k := math.Sqrt(mapWidth * mapHeight / float64(len(regions)))
+	temperature := mapWidth / 10
+	for step := 0; step < layoutSteps; step++ {
+		moves := map[string]Point{}
+		for _, a := range free {
+			move := Point{}
+			for _, b := range regions {
+				if a == b {
+					continue
+				}
+				dx := positions[a].X - positions[b].X
+				dy := positions[a].Y - positions[b].Y
+				distance := math.Max(math.Hypot(dx, dy), 0.01)
+				force := k * k / distance
+				move.X += dx / distance * force
+				move.Y += dy / distance * force
+			}
+			moves[a] = move
+		}
+		for _, edge := range edges {
+			dx := positions[edge.From].X - positions[edge.To].X
+			dy := positions[edge.From].Y - positions[edge.To].Y
+			distance := math.Max(math.Hypot(dx, dy), 0.01)
+			force := distance * distance / k
+			if move, ok := moves[edge.From]; ok {
+				move.X -= dx / distance * force
+				move.Y -= dy / distance * force
+				moves[edge.From] = move
+			}
+			if move, ok := moves[edge.To]; ok {
+				move.X += dx / distance * force
+				move.Y += dy / distance * force
+				moves[edge.To] = move
+			}
+		}
+		for _, region := range free {
+			move := moves[region]
+			length := math.Max(math.Hypot(move.X, move.Y), 0.01)
+			limited := math.Min(length, temperature)
+			point := positions[region]
+			point.X = math.Min(math.Max(point.X+move.X/length*limited, mapMargin), mapWidth-mapMargin)
+			point.Y = math.Min(math.Max(point.Y+move.Y/length*limited, mapMargin), mapHeight-mapMargin)
+			positions[region] = point
+		}
+		temperature *= 0.98
+	}
+	return snapToGrid(positions, free, fixed)
+}
+
+// snapToGrid moves each free region to the closest grid cell that isn't already taken
+func snapToGrid(positions map[string]Point, free []string, fixed map[string]Point) map[string]Point {
+	columns := int(math.Floor(mapWidth / cellWidth))
+	rows := int(math.Floor(mapHeight / cellHeight))
+	cellCenter := func(column, row int) Point {
+		return Point{
+			X: (float64(column) + 0.5) * mapWidth / float64(columns),
+			Y: (float64(row) + 0.5) * mapHeight / float64(rows),
+		}
+	}
+
+	taken := map[[2]int]bool{}
+	for _, point := range fixed {
+		column := int(point.X / (mapWidth / float64(columns)))
+		row := int(point.Y / (mapHeight / float64(rows)))
+		taken[[2]int{column, row}] = true
+	}
+	for _, region := range free {
+		point := positions[region]
+		best := [2]int{-1, -1}
+		bestDistance := math.Inf(1)
+		for column := 0; column < columns; column++ {
+			for row := 0; row < rows; row++ {
+				if taken[[2]int{column, row}] {
+					continue
+				}
+				center := cellCenter(column, row)
+				distance := math.Hypot(center.X-point.X, center.Y-point.Y)
+				if distance < bestDistance {
+					best = [2]int{column, row}
+					bestDistance = distance
+				}
+			}
+		}
+		// more regions than cells, leave it where the forces put it
+		if best[0] < 0 {
+			continue
+		}
+		taken[best] = true
+		positions[region] = cellCenter(best[0], best[1])
+	}
+	return positions
+}
+
+// RenderSVG draws every region with its entrance and check counters, and the discovered connections
+// between them. regions without coordinates in layout are placed automatically
+func RenderSVG(save Save, layout map[string]Point) string {
+	regions := PackFor(save).SceneNames()
+	edges := collectGraph(save).edges
+	positions := forceLayout(regions, edges, layout)
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="sans-serif">`+"\n",
+		mapWidth, mapHeight, mapWidth, mapHeight)
+	fmt.Fprintf(b, `<rect width="100%%" height="100%%" fill="#1d1f21"/>`+"\n")
+
+	b.WriteString(`<g stroke="#8abeb7" stroke-width="2">` + "\n")
+	for _, edge := range edges {
+		from, to := positions[edge.From], positions[edge.To]
+		fmt.Fprintf(b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f"><title>%s</title></line>`+"\n",
+			from.X, from.Y, to.X, to.Y, html.EscapeString(edgeLabel(edge)))
+	}
+	b.WriteString("</g>\n")
+
+	for _, region := range regions {
+		point := positions[region]
+		totals := save.Scenes[region].Totals
+		fill := "#373b41"
+		if totals.Entrances.Undiscovered == 0 && totals.Checks.Undiscovered == 0 {
+			fill = "#5f819d"
+		}
+		fmt.Fprintf(b, `<g transform="translate(%.1f,%.1f)">`+"\n", point.X-nodeWidth/2, point.Y-nodeHeight/2)
+		fmt.Fprintf(b, `<rect width="%.0f" height="%.0f" rx="6" fill="%s" stroke="#c5c8c6"/>`+"\n", nodeWidth, nodeHeight, fill)
+		fmt.Fprintf(b, `<text x="%.0f" y="18" font-size="12" fill="#ffffff" text-anchor="middle">%s</text>`+"\n",
+			nodeWidth/2, html.EscapeString(region))
+		fmt.Fprintf(b, `<text x="%.0f" y="35" font-size="10" fill="#c5c8c6" text-anchor="middle">doors %d/%d · checks %d/%d</text>`+"\n",
+			nodeWidth/2,
+			totals.Entrances.Total-totals.Entrances.Undiscovered, totals.Entrances.Total,
+			totals.Checks.Total-totals.Checks.Undiscovered, totals.Checks.Total,
+		)
+		b.WriteString("</g>\n")
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
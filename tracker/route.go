@@ -0,0 +1,135 @@
+package tracker
+
+import (
+	"fmt"
+	"sort"
+)
+
+type (
+	Step struct {
+		From   string
+		Door   string
+		To     string
+		ToDoor string
+		Warp   bool
+	}
+	Route struct {
+		From  string
+		To    string
+		Steps []Step
+	}
+)
+
+const (
+	// RouteCurrent and RouteRespawn can be passed to FindRoute in place of a scene name
+	RouteCurrent = "current"
+	RouteRespawn = "respawn"
+
+	dathDoor = "Dath Stone"
+)
+
+// resolveScene turns the current/respawn shorthands into real scene names and makes sure the scene exists
+func resolveScene(save Save, scene string) (string, error) {
+	switch scene {
+	case RouteCurrent:
+		scene = save.Current.Scene
+	case RouteRespawn:
+		scene = save.Current.Respawn
+	}
+	if scene == "" {
+		return "", fmt.Errorf("no scene given, or the save has no matching scene yet")
+	}
+	if _, ok := save.Scenes[scene]; !ok {
+		return "", fmt.Errorf("unrecognized scene: %s", scene)
+	}
+	return scene, nil
+}
+
+// buildGraph collects every discovered transition in both directions, keyed by the scene it leaves from
+func buildGraph(save Save) map[string][]Step {
+	graph := map[string][]Step{}
+	seen := map[Step]struct{}{}
+	add := func(step Step) {
+		if _, ok := seen[step]; ok {
+			return
+		}
+		seen[step] = struct{}{}
+		graph[step.From] = append(graph[step.From], step)
+	}
+
+	for scene, data := range save.Scenes {
+		for door, destination := range data.Entrances {
+			if destination.Scene == "" || destination.Scene == UnknownScene {
+				continue
+			}
+			add(Step{From: scene, Door: door, To: destination.Scene, ToDoor: destination.Door})
+			// shop portals are listed on the shop side only, so don't invent a fake door on the way back
+			if scene == "Shop" {
+				continue
+			}
+			add(Step{From: destination.Scene, Door: destination.Door, To: scene, ToDoor: door})
+		}
+	}
+
+	// keep the search deterministic so the same state always produces the same route
+	for scene := range graph {
+		sort.Slice(graph[scene], func(i, j int) bool {
+			return graph[scene][i].Door < graph[scene][j].Door
+		})
+	}
+	return graph
+}
+
+// FindRoute returns the shortest path through discovered transitions between two scenes.
+// when useDath is set and the save has the Dath Stone, warping to its campfire is treated as a free move
+func FindRoute(save Save, from, to string, useDath bool) (Route, error) {
+	start, err := resolveScene(save, from)
+	if err != nil {
+		return Route{}, err
+	}
+	end, err := resolveScene(save, to)
+	if err != nil {
+		return Route{}, err
+	}
+	route := Route{
+		From:  start,
+		To:    end,
+		Steps: []Step{},
+	}
+	if start == end {
+		return route, nil
+	}
+
+	graph := buildGraph(save)
+	warp := useDath && save.Current.HasDath && save.Current.Dath != ""
+
+	// breadth first search, remembering how we got to each scene
+	previous := map[string]Step{}
+	visited := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		scene := queue[0]
+		queue = queue[1:]
+
+		steps := graph[scene]
+		if warp {
+			steps = append([]Step{{From: scene, Door: dathDoor, To: save.Current.Dath, Warp: true}}, steps...)
+		}
+		for _, step := range steps {
+			if visited[step.To] {
+				continue
+			}
+			visited[step.To] = true
+			previous[step.To] = step
+			if step.To == end {
+				// walk backwards to rebuild the path
+				for current := end; current != start; current = previous[current].From {
+					route.Steps = append([]Step{previous[current]}, route.Steps...)
+				}
+				return route, nil
+			}
+			queue = append(queue, step.To)
+		}
+	}
+	return Route{}, fmt.Errorf("no known route from %s to %s", start, end)
+}
@@ -0,0 +1,123 @@
+package tracker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type (
+	// SaveHeader is the seed and randomizer settings of a save, enough to tell save slots apart
+	SaveHeader struct {
+		Seed          string
+		Archipelago   bool
+		Randomized    bool
+		HexQuest      bool
+		EntranceRando bool
+		FixedShops    bool
+		// RandomizerVersion is the version of the randomizer that wrote the save, if it says
+		RandomizerVersion string
+	}
+	// SaveFile is everything read out of a .tunic save, before it is matched against a spoiler log
+	SaveFile struct {
+		SaveHeader
+		// scene fields hold the raw unity scene names, see TranslateScene
+		Scene     string
+		Respawn   string
+		Dath      string
+		Portals   []string
+		Inventory map[string]int
+		Flags     map[string]string
+	}
+)
+
+const inventoryPrefix = "inventory quantity "
+
+// splitFlag separates a save line into its key and value
+func splitFlag(line string) (string, string) {
+	parts := strings.SplitN(line, "|", 2)
+	if len(parts) < 2 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// parseHeaderLine applies a save line to the header, returning whether it was a header line
+func parseHeaderLine(header *SaveHeader, line string) bool {
+	// easy checks first
+	if line == "archipelago|1" {
+		header.Archipelago = true
+		header.Randomized = true
+	} else if line == "randomizer|1" {
+		header.Randomized = true
+	} else if line == "randomizer hexagon quest enabled|1" {
+		header.HexQuest = true
+	} else if line == "randomizer entrance rando enabled|1" {
+		header.EntranceRando = true
+	} else if line == "randomizer ER fixed shop|1" {
+		header.FixedShops = true
+	} else if strings.HasPrefix(line, "seed|") {
+		_, header.Seed = splitFlag(line)
+	} else if strings.HasPrefix(line, "randomizer version|") {
+		_, header.RandomizerVersion = splitFlag(line)
+	} else {
+		return false
+	}
+	return true
+}
+
+// ParseSaveHeader reads only the seed and randomizer settings of a .tunic save
+func ParseSaveHeader(r io.Reader) (SaveHeader, error) {
+	header := SaveHeader{}
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanLines)
+	for scanner.Scan() {
+		parseHeaderLine(&header, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return SaveHeader{}, fmt.Errorf("Failed to read save file: %w", err)
+	}
+	return header, nil
+}
+
+// ParseSaveFile reads a .tunic save. it does no validation against the location tables, that happens in Combine
+func ParseSaveFile(r io.Reader) (SaveFile, error) {
+	save := SaveFile{
+		Portals:   []string{},
+		Inventory: map[string]int{},
+		Flags:     map[string]string{},
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanLines)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		key, value := splitFlag(line)
+		save.Flags[key] = value
+
+		if parseHeaderLine(&save.SaveHeader, line) {
+			continue
+		} else if key == "last spawn scene name" {
+			save.Scene = value
+		} else if key == "last campfire scene name" {
+			save.Respawn = value
+		} else if key == "randomizer last campfire scene name for dath stone" {
+			save.Dath = value
+		} else if strings.HasPrefix(key, inventoryPrefix) {
+			if quantity, err := strconv.Atoi(value); err == nil {
+				save.Inventory[strings.TrimPrefix(key, inventoryPrefix)] = quantity
+			}
+		}
+
+		matches := portalRegex.FindStringSubmatch(line)
+		if len(matches) > 1 {
+			save.Portals = append(save.Portals, matches[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return SaveFile{}, fmt.Errorf("Failed to read save file: %w", err)
+	}
+	return save, nil
+}
@@ -0,0 +1,54 @@
+package tracker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SaveListing describes one save slot in the SAVES directory
+type SaveListing struct {
+	Name    string
+	ModTime time.Time
+	SaveHeader
+}
+
+// ListSaves reads the header of every .tunic file in the SAVES directory, most recently written first
+func ListSaves(saves string) ([]SaveListing, error) {
+	files, err := os.ReadDir(saves)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read saves directory: %w", err)
+	}
+	listings := []SaveListing{}
+	for _, file := range files {
+		name := file.Name()
+		if !strings.HasSuffix(name, ".tunic") {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		f, err := os.Open(filepath.Join(saves, name))
+		if err != nil {
+			continue
+		}
+		header, err := ParseSaveHeader(f)
+		f.Close()
+		if err != nil {
+			continue
+		}
+		listings = append(listings, SaveListing{
+			Name:       name,
+			ModTime:    info.ModTime(),
+			SaveHeader: header,
+		})
+	}
+	sort.Slice(listings, func(i, j int) bool {
+		return listings[i].ModTime.After(listings[j].ModTime)
+	})
+	return listings, nil
+}
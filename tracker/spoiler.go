@@ -0,0 +1,109 @@
+package tracker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type (
+	Placement struct {
+		Scene string
+		Check string
+		Found bool
+		// the item exactly as written in the spoiler, which includes the receiving player in archipelago
+		Item string
+	}
+	EntrancePair struct {
+		From string
+		To   string
+	}
+	// SpoilerLog is everything read out of a Spoiler.log, before it is matched against a save
+	SpoilerLog struct {
+		Seed string
+		// RandomizerVersion is the version of the randomizer that wrote the log, if it says
+		RandomizerVersion string
+		// HexagonGoal is how many gold hexagons hexagon quest needs, if the log says
+		HexagonGoal int
+		Placements  []Placement
+		// the "Major Items" section repeats the placements of the important items
+		MajorItems []Placement
+		Entrances  []EntrancePair
+		// destinations of every shop portal, in the order they are listed
+		Shops []string
+	}
+)
+
+// ParseSpoilerLog reads a Spoiler.log. it does no validation against the location tables, that happens in Combine
+func ParseSpoilerLog(r io.Reader) (SpoilerLog, error) {
+	spoiler := SpoilerLog{
+		Placements: []Placement{},
+		MajorItems: []Placement{},
+		Entrances:  []EntrancePair{},
+		Shops:      []string{},
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanLines)
+
+	majorItems := false
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		// the "Major Items" section repeats lines from the rest of the log, so keep it apart from the real totals
+		if majorItems && strings.HasPrefix(line, "\t") {
+			if matches := itemRegex.FindStringSubmatch(line); len(matches) > 0 {
+				spoiler.MajorItems = append(spoiler.MajorItems, Placement{
+					Scene: matches[2],
+					Check: matches[3],
+					Found: matches[1] != "-",
+					Item:  matches[4],
+				})
+			}
+			continue
+		}
+		majorItems = false
+		if line == "Major Items" {
+			majorItems = true
+			continue
+		}
+		// look for the specific seed of the spoiler
+		if strings.HasPrefix(line, "Seed: ") {
+			spoiler.Seed = strings.TrimPrefix(line, "Seed: ")
+		}
+		if matches := versionRegex.FindStringSubmatch(line); matches != nil {
+			spoiler.RandomizerVersion = matches[1]
+		}
+		// hexagon quest goal, which the save may also carry
+		if strings.HasPrefix(line, "Hexagon Quest Goal: ") {
+			if goal, err := strconv.Atoi(strings.TrimPrefix(line, "Hexagon Quest Goal: ")); err == nil {
+				spoiler.HexagonGoal = goal
+			}
+		}
+		// check if this is an item line
+		matches := itemRegex.FindStringSubmatch(line)
+		if len(matches) > 0 {
+			spoiler.Placements = append(spoiler.Placements, Placement{
+				Scene: matches[2],
+				Check: matches[3],
+				Found: matches[1] != "-",
+				Item:  matches[4],
+			})
+			continue
+		}
+		// check if this is an entrance connection line
+		matches = entranceRegex.FindStringSubmatch(line)
+		if len(matches) > 0 {
+			// keep a separate listing of shop entrances
+			if matches[2] == "Shop" || matches[2] == "Shop Portal" {
+				spoiler.Shops = append(spoiler.Shops, matches[1])
+			}
+			spoiler.Entrances = append(spoiler.Entrances, EntrancePair{matches[1], matches[2]})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return SpoilerLog{}, fmt.Errorf("Failed to read spoiler log: %w", err)
+	}
+	return spoiler, nil
+}
@@ -0,0 +1,152 @@
+package tracker
+
+import (
+	"fmt"
+	"sort"
+)
+
+// TableProblem is an inconsistency between the tables of a data pack
+type TableProblem struct {
+	// Fatal problems give wrong answers at runtime, the rest are worth a look
+	Fatal   bool
+	Pack    string
+	Table   string
+	Key     string
+	Message string
+}
+
+func (p TableProblem) String() string {
+	return fmt.Sprintf("pack %s: %s[%q]: %s", p.Pack, p.Table, p.Key, p.Message)
+}
+
+// globalCodes is the DataPack.Codes family for codes that don't belong to any scene
+const globalCodes = "Global"
+
+// genericDoors are the shop doors every shop shares, which the spoiler log never pairs with one door
+var genericDoors = map[string]bool{"Shop": true, "Shop Portal": true}
+
+// ValidateTables validates every known data pack
+func ValidateTables() []TableProblem {
+	problems := []TableProblem{}
+	for _, pack := range DataPacks() {
+		problems = append(problems, pack.Validate()...)
+	}
+	return problems
+}
+
+// Validate cross-checks the pack's scenes, doors, regions, codes, requirements and progression items against
+// each other, and reports orphaned doors, duplicates and regions that disagree. the result is sorted so it can
+// be diffed
+func (p *DataPack) Validate() []TableProblem {
+	problems := []TableProblem{}
+	fail := func(table, key, message string, args ...interface{}) {
+		problems = append(problems, TableProblem{true, p.Version, table, key, fmt.Sprintf(message, args...)})
+	}
+	warn := func(table, key, message string, args ...interface{}) {
+		problems = append(problems, TableProblem{false, p.Version, table, key, fmt.Sprintf(message, args...)})
+	}
+
+	scenes := map[string]string{}
+	for _, raw := range sortedKeys(p.Scenes) {
+		scene := p.Scenes[raw]
+		if other, ok := scenes[scene]; ok {
+			warn("scenes", raw, "translates to %s, the same as %s", scene, other)
+			continue
+		}
+		scenes[scene] = raw
+	}
+
+	// where doors puts each door, to compare against regions
+	areas := map[string]string{}
+	for _, area := range sortedKeys(p.Doors) {
+		if _, ok := scenes[area]; !ok {
+			fail("doors", area, "is not a scene in scenes")
+		}
+		for _, door := range p.Doors[area] {
+			if other, ok := areas[door]; ok {
+				if other == area {
+					warn("doors", area, "lists %s more than once", door)
+				} else {
+					fail("doors", area, "lists %s, which is already in %s", door, other)
+				}
+				continue
+			}
+			areas[door] = area
+		}
+	}
+
+	for _, door := range sortedKeys(areas) {
+		if genericDoors[door] {
+			continue
+		}
+		area := areas[door]
+		region, ok := p.Regions[door]
+		if !ok {
+			fail("doors", area, "lists %s, which is missing from regions", door)
+			continue
+		}
+		// check GetDoorArea rather than the map directly, since it is what the tracker asks
+		if found, err := p.GetDoorArea(door); err != nil || found != region {
+			fail("regions", door, "is in %s, but GetDoorArea says %s", region, found)
+		}
+	}
+	for _, door := range sortedKeys(p.Regions) {
+		region := p.Regions[door]
+		if _, ok := scenes[region]; !ok {
+			fail("regions", door, "is in %s, which is not a scene in scenes", region)
+		}
+		if _, ok := areas[door]; !ok && !genericDoors[door] {
+			fail("regions", door, "is missing from doors")
+		}
+	}
+
+	flags := map[string]string{}
+	for _, family := range sortedKeys(p.Codes) {
+		if _, ok := scenes[family]; !ok && family != globalCodes {
+			fail("codes", family, "is not a scene in scenes")
+		}
+		for _, code := range sortedKeys(p.Codes[family]) {
+			flag := p.Codes[family][code]
+			if other, ok := flags[flag]; ok {
+				warn("codes", family, "%s uses the flag %s, the same as %s", code, flag, other)
+				continue
+			}
+			flags[flag] = family + " " + code
+		}
+	}
+
+	for _, door := range sortedKeys(p.Requirements) {
+		if _, ok := p.Regions[door]; !ok {
+			fail("requirements", door, "is missing from regions")
+		}
+		code := p.Requirements[door].Code
+		if code == ([2]string{}) {
+			continue
+		}
+		if _, ok := p.Codes[code[0]][code[1]]; !ok {
+			fail("requirements", door, "needs the code %s %s, which is missing from codes", code[0], code[1])
+		}
+	}
+	for _, keyword := range sortedKeys(p.CheckKeywords) {
+		code := p.CheckKeywords[keyword].Code
+		if code == ([2]string{}) {
+			continue
+		}
+		if _, ok := p.Codes[code[0]][code[1]]; !ok {
+			fail("checkKeywords", keyword, "needs the code %s %s, which is missing from codes", code[0], code[1])
+		}
+	}
+	for _, bomb := range p.Progression.Bombs {
+		if _, ok := p.Codes[globalCodes][bomb]; !ok {
+			fail("progression", "bombs", "lists %s, which is missing from the %s codes", bomb, globalCodes)
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Table != problems[j].Table {
+			return problems[i].Table < problems[j].Table
+		}
+		return problems[i].Key < problems[j].Key
+	})
+	return problems
+}
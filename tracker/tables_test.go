@@ -0,0 +1,166 @@
+package tracker
+
+import (
+	"testing"
+)
+
+func TestEmbeddedPacksValidate(t *testing.T) {
+	for _, problem := range ValidateTables() {
+		if problem.Fatal {
+			t.Errorf("%s", problem)
+		}
+	}
+}
+
+// testPack is a tiny pack with one of everything Validate looks at, and nothing wrong with it
+func testPack() *DataPack {
+	return &DataPack{
+		Version: "test",
+		Scenes: map[string]string{
+			"Overworld Redux": "Overworld",
+			"Sword Cave":      "Sword Cave",
+		},
+		Doors: map[string][]string{
+			"Overworld":  {"Overworld to Sword Cave"},
+			"Sword Cave": {"Sword Cave Exit"},
+		},
+		Regions: map[string]string{
+			"Overworld to Sword Cave": "Overworld",
+			"Sword Cave Exit":         "Sword Cave",
+		},
+		Codes: map[string]map[string]string{
+			"Overworld": {"Fountain Cross Door": "Fountain Cross Door Opened|1"},
+			globalCodes: {"Firecracker": "Granted Firecracker|1"},
+		},
+		Requirements: map[string]Requirement{
+			"Overworld to Sword Cave": {HolyCross: true, Code: [2]string{"Overworld", "Fountain Cross Door"}},
+		},
+		CheckKeywords: map[string]Requirement{
+			"Dash": {Laurels: true},
+		},
+		Progression: ProgressionItems{
+			Bombs: []string{"Firecracker"},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if problems := testPack().Validate(); len(problems) != 0 {
+		t.Fatalf("the test pack should be clean, got %v", problems)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(p *DataPack)
+		table  string
+		key    string
+		fatal  bool
+	}{
+		{
+			name:   "door missing from regions",
+			mutate: func(p *DataPack) { p.Doors["Sword Cave"] = append(p.Doors["Sword Cave"], "Sword Cave Back Door") },
+			table:  "doors",
+			key:    "Sword Cave",
+			fatal:  true,
+		},
+		{
+			name:   "region missing from doors",
+			mutate: func(p *DataPack) { p.Regions["Sword Cave Back Door"] = "Sword Cave" },
+			table:  "regions",
+			key:    "Sword Cave Back Door",
+			fatal:  true,
+		},
+		{
+			name:   "door in two scenes",
+			mutate: func(p *DataPack) { p.Doors["Sword Cave"] = append(p.Doors["Sword Cave"], "Overworld to Sword Cave") },
+			table:  "doors",
+			key:    "Sword Cave",
+			fatal:  true,
+		},
+		{
+			name:   "door listed twice in one scene",
+			mutate: func(p *DataPack) { p.Doors["Overworld"] = append(p.Doors["Overworld"], "Overworld to Sword Cave") },
+			table:  "doors",
+			key:    "Overworld",
+			fatal:  false,
+		},
+		{
+			name:   "scenes translating to the same name",
+			mutate: func(p *DataPack) { p.Scenes["Overworld Interiors"] = "Overworld" },
+			table:  "scenes",
+			key:    "Overworld Redux",
+			fatal:  false,
+		},
+		{
+			name:   "region disagreeing with doors",
+			mutate: func(p *DataPack) { p.Regions["Sword Cave Exit"] = "Overworld" },
+			table:  "regions",
+			key:    "Sword Cave Exit",
+			fatal:  true,
+		},
+		{
+			name:   "region that is not a scene",
+			mutate: func(p *DataPack) { p.Regions["Sword Cave Exit"] = "Sword Cavern" },
+			table:  "regions",
+			key:    "Sword Cave Exit",
+			fatal:  true,
+		},
+		{
+			name:   "doors of a scene that doesn't exist",
+			mutate: func(p *DataPack) { p.Doors["Sword Cavern"] = []string{} },
+			table:  "doors",
+			key:    "Sword Cavern",
+			fatal:  true,
+		},
+		{
+			name:   "code family that is not a scene",
+			mutate: func(p *DataPack) { p.Codes["Sword Cavern"] = map[string]string{"Cave Fairy": "Cave Fairy Revealed|1"} },
+			table:  "codes",
+			key:    "Sword Cavern",
+			fatal:  true,
+		},
+		{
+			name: "two codes sharing a flag",
+			mutate: func(p *DataPack) {
+				p.Codes["Overworld"]["Fountain Cross Door Again"] = "Fountain Cross Door Opened|1"
+			},
+			table: "codes",
+			key:   "Overworld",
+			fatal: false,
+		},
+		{
+			name:   "requirement on an unknown door",
+			mutate: func(p *DataPack) { p.Requirements["Sword Cave Back Door"] = Requirement{Laurels: true} },
+			table:  "requirements",
+			key:    "Sword Cave Back Door",
+			fatal:  true,
+		},
+		{
+			name:   "requirement on a missing code",
+			mutate: func(p *DataPack) { delete(p.Codes["Overworld"], "Fountain Cross Door") },
+			table:  "requirements",
+			key:    "Overworld to Sword Cave",
+			fatal:  true,
+		},
+		{
+			name:   "bomb missing from the global codes",
+			mutate: func(p *DataPack) { p.Progression.Bombs = append(p.Progression.Bombs, "Icebomb") },
+			table:  "progression",
+			key:    "bombs",
+			fatal:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pack := testPack()
+			test.mutate(pack)
+			problems := pack.Validate()
+			for _, problem := range problems {
+				if problem.Table == test.table && problem.Key == test.key && problem.Fatal == test.fatal {
+					return
+				}
+			}
+			t.Errorf("expected a problem in %s[%q] with fatal %v, got %v", test.table, test.key, test.fatal, problems)
+		})
+	}
+}
@@ -1,15 +1,15 @@
 package tracker
 
 import (
-	"bufio"
 	"crypto/md5"
 	"encoding/hex"
 	"entrance1/log"
+	"entrance1/store"
 	"fmt"
 	"os"
 	"path"
 	"regexp"
-	"strings"
+	"sort"
 	"time"
 
 	"go.uber.org/zap"
@@ -22,11 +22,16 @@ type (
 		Seed          string
 		SpoilerSeed   string
 		SpoilerMod    time.Time
+		SaveMod       time.Time
 		Archipelago   bool
 		Randomized    bool
 		HexQuest      bool
 		EntranceRando bool
 		FixedShops    bool
+		// RandomizerVersion comes from the spoiler log, or the save when the log doesn't say
+		RandomizerVersion string
+		// DataPack is the version of the scene, door and code tables used, see DataPack
+		DataPack string
 	}
 	Total struct {
 		Total        int
@@ -39,11 +44,18 @@ type (
 	Door struct {
 		Scene string
 		Door  string
+		// Source is where the destination came from: SourceSpoiler, SourceSave or SourceManual
+		Source  string `json:",omitempty"`
+		DeadEnd bool   `json:",omitempty"`
+		Note    string `json:",omitempty"`
 	}
 	Scene struct {
 		Totals    Totals
 		Checks    map[string]bool
 		Entrances map[string]Door
+		Logic     Logic
+		// Items is kept out of the main blob so it doesn't spoil every check, see CheckItems
+		Items map[string]Item `json:"-"`
 	}
 	Current struct {
 		Scene      string
@@ -58,37 +70,76 @@ type (
 		Current Current
 		Scenes  map[string]Scene
 		Codes   map[string]map[string]bool
+		// MajorItems is where the spoiler log says the important items are, in the order it lists them
+		MajorItems []MajorItem
+		// Hexagons is only set in hexagon quest
+		Hexagons *Hexagons
+		// Inventory is every "inventory quantity" flag in the save
+		Inventory   map[string]int
+		Progression Progression
+		// Mismatch is set when the save and the spoiler log come from different seeds
+		Mismatch Mismatch
+	}
+	// Warning is a problem found while combining a spoiler log and a save that didn't stop the parse
+	Warning struct {
+		Message string
+		Details map[string]string
 	}
 )
 
+// UnknownScene marks a door that has been walked through but whose destination can't be known without the spoiler log
+const UnknownScene = "Unknown"
+
 var (
 	portalRegex   = regexp.MustCompile(`^randomizer entered portal ([^|]+)\|1$`)
 	entranceRegex = regexp.MustCompile(`\s+- (.+) -- (.+)$`)
-	itemRegex     = regexp.MustCompile(`^\s+([-x]) ([^-]+) - ([^:]+): `)
+	itemRegex     = regexp.MustCompile(`^\s+([-x]) ([^-]+) - ([^:]+): (.*)$`)
+	versionRegex  = regexp.MustCompile(`^(?i)(?:tunic )?(?:randomizer )?version:?\s+v?(\d\S*)$`)
 
-	State Save
+	State = store.New(Save{})
+	// NoSpoilerState is rebuilt from the save file alone, for races run without a spoiler log
+	NoSpoilerState = store.New(Save{})
 )
 
-func getSceneFromFlag(flag string) string {
-	rawScene := strings.Split(flag, "|")[1]
-	scene, err := TranslateScene(rawScene)
-	if err != nil {
-		log.Log.Error("Failed to translate current scene",
-			zap.String("scene", rawScene),
-		)
+func newWarning(message string, details ...string) Warning {
+	warning := Warning{
+		Message: message,
+		Details: map[string]string{},
 	}
-	return scene
+	for i := 0; i+1 < len(details); i += 2 {
+		warning.Details[details[i]] = details[i+1]
+	}
+	return warning
 }
 
-func ParseWithSpoiler(recent, saves, spoilerLoc string) error {
+// logWarnings hands warnings from Combine to the global logger
+func logWarnings(warnings []Warning) {
+	for _, warning := range warnings {
+		keys := []string{}
+		for key := range warning.Details {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fields := []zap.Field{}
+		for _, key := range keys {
+			fields = append(fields, zap.String(key, warning.Details[key]))
+		}
+		log.Log.Warn(warning.Message, fields...)
+	}
+}
+
+// newSave returns an empty payload with every scene and code family in pack populated
+func newSave(pack *DataPack) Save {
 	payload := Save{
 		Debug:  Debug{},
 		Totals: Totals{},
 		Scenes: map[string]Scene{},
 		Codes:  map[string]map[string]bool{},
+
+		MajorItems: []MajorItem{},
 	}
 	// populate our payload with every scene
-	for _, a := range sceneNames {
+	for _, a := range pack.Scenes {
 		payload.Scenes[a] = Scene{
 			Totals: Totals{
 				Entrances: Total{},
@@ -96,199 +147,58 @@ func ParseWithSpoiler(recent, saves, spoilerLoc string) error {
 			},
 			Checks:    map[string]bool{},
 			Entrances: map[string]Door{},
+			Items:     map[string]Item{},
 		}
 	}
-	spoiler := map[string]string{}
-
 	// populate our payload with each code family
-	for family, section := range codesByScene {
+	for family, section := range pack.Codes {
 		payload.Codes[family] = map[string]bool{}
 		for code := range section {
 			payload.Codes[family][code] = false
 		}
 	}
+	return payload
+}
 
-	// get spoiler.log update time
-	spoilerStat, err := os.Stat(spoilerLoc)
-	if err != nil {
-		log.Log.Error("Failed to get spoiler log stats",
-			zap.String("spoiler location", spoilerLoc),
-			zap.Error(err),
-		)
-		return fmt.Errorf("Failed to stat spoiler log: %w", err)
+// translateCurrent turns a raw scene flag from the save into a scene name, warning when it isn't known
+func translateCurrent(pack *DataPack, raw string, warnings *[]Warning) string {
+	if raw == "" {
+		return ""
 	}
-	payload.Debug.SpoilerMod = spoilerStat.ModTime()
-
-	hash := md5.Sum([]byte(recent + spoilerStat.ModTime().String()))
-	payload.Debug.Hash = hex.EncodeToString(hash[:])
-
-	// open spoiler.log
-	spoilerReader, err := os.Open(spoilerLoc)
+	scene, err := pack.TranslateScene(raw)
 	if err != nil {
-		log.Log.Error("Failed to open spoiler log",
-			zap.String("spoiler location", spoilerLoc),
-			zap.Error(err),
-		)
-		return fmt.Errorf("Failed to open spoiler log: %w", err)
+		*warnings = append(*warnings, newWarning("Failed to translate current scene", "scene", raw))
 	}
-	spoilerScanner := bufio.NewScanner(spoilerReader)
-	spoilerScanner.Split(bufio.ScanLines)
-
-	shopList := []string{}
-	quiesce := false
-
-	for spoilerScanner.Scan() {
-		line := spoilerScanner.Text()
-		// skip the "Major Items" section of the spoiler log
-		if quiesce && strings.HasPrefix(line, "\t") {
-			continue
-		}
-		quiesce = false
-		if line == "Major Items" {
-			quiesce = true
-			continue
-		}
-		// look for the specific seed of the spoiler
-		if strings.HasPrefix(line, "Seed: ") {
-			payload.Debug.SpoilerSeed = strings.TrimPrefix(line, "Seed: ")
-		}
-		// check if this is an item line
-		matches := itemRegex.FindStringSubmatch(line)
-		if len(matches) > 0 {
-			found := matches[1] != "-"
-			_, ok := payload.Scenes[matches[2]]
-			if !ok {
-				log.Log.Warn("Ignoring unknown check location",
-					zap.String("location", matches[2]),
-				)
-				continue
-			}
-
-			payload.Totals.Checks.Total++
-			temp := payload.Scenes[matches[2]]
-			temp.Totals.Checks.Total++
-			temp.Checks[matches[3]] = found
-			if !found {
-				payload.Totals.Checks.Undiscovered++
-				temp.Totals.Checks.Undiscovered++
-			}
-			payload.Scenes[matches[2]] = temp
-		}
-		// check if this is an entrance connection line
-		matches = entranceRegex.FindStringSubmatch(line)
-		if len(matches) > 0 {
-			// keep a separate listing of shop entrances
-			if matches[2] == "Shop" || matches[2] == "Shop Portal" {
-				shopList = append(shopList, matches[1])
-			}
-			payload.Totals.Entrances.Total += 2
-			spoiler[matches[1]] = matches[2]
-			spoiler[matches[2]] = matches[1]
-		}
-	}
-	spoilerReader.Close()
-
-	// open save file
-	payload.Debug.Name = recent
-	saveReader, err := os.Open(path.Join(saves, recent))
-	if err != nil {
-		log.Log.Error("Failed to open spoiler log",
-			zap.String("save location", saves),
-			zap.String("most recent", recent),
-			zap.Error(err),
-		)
-		return fmt.Errorf("Failed to open most recent save file: %w", err)
-	}
-
-	saveScanner := bufio.NewScanner(saveReader)
-	saveScanner.Split(bufio.ScanLines)
-	entrances := map[string]struct{}{}
-
-	foundShop := false
+	return scene
+}
 
-	for saveScanner.Scan() {
-		line := saveScanner.Text()
-
-		// easy checks first
-		if line == "archipelago|1" {
-			payload.Debug.Archipelago = true
-			payload.Debug.Randomized = true
-		} else if line == "randomizer|1" {
-			payload.Debug.Randomized = true
-		} else if line == "randomizer hexagon quest enabled|1" {
-			payload.Debug.HexQuest = true
-		} else if line == "randomizer entrance rando enabled|1" {
-			payload.Debug.EntranceRando = true
-		} else if line == "randomizer ER fixed shop|1" {
-			payload.Debug.FixedShops = true
-		} else if line == "inventory quantity Dath Stone|1" {
-			payload.Current.HasDath = true
-		} else if line == "inventory quantity Hyperdash|1" {
-			payload.Current.HasLaurels = true
-		} else if strings.HasPrefix(line, "seed|") {
-			payload.Debug.Seed = strings.Split(line, "|")[1]
-		} else if strings.HasPrefix(line, "last spawn scene name|") {
-			payload.Current.Scene = getSceneFromFlag(line)
-		} else if strings.HasPrefix(line, "last campfire scene name|") {
-			payload.Current.Respawn = getSceneFromFlag(line)
-		} else if strings.HasPrefix(line, "randomizer last campfire scene name for dath stone|") {
-			payload.Current.Dath = getSceneFromFlag(line)
-		}
+// applySaveFile copies every field that doesn't depend on the spoiler log into the payload
+func applySaveFile(payload *Save, pack *DataPack, save SaveFile, warnings *[]Warning) {
+	payload.Debug.Seed = save.Seed
+	payload.Debug.Archipelago = save.Archipelago
+	payload.Debug.Randomized = save.Randomized
+	payload.Debug.HexQuest = save.HexQuest
+	payload.Debug.EntranceRando = save.EntranceRando
+	payload.Debug.FixedShops = save.FixedShops
 
-		// holy cross code flags
-		for family, section := range codesByScene {
-			for check, code := range section {
-				if line == code {
-					payload.Codes[family][check] = true
-				}
-			}
-		}
+	payload.Current.Scene = translateCurrent(pack, save.Scene, warnings)
+	payload.Current.Respawn = translateCurrent(pack, save.Respawn, warnings)
+	payload.Current.Dath = translateCurrent(pack, save.Dath, warnings)
 
-		matches := portalRegex.FindStringSubmatch(line)
-		if len(matches) > 1 {
-			entrances[matches[1]] = struct{}{}
-			// don't add mapping here, but remember we found the shop(s)
-			if matches[1] == "Shop" {
-				foundShop = true
-				continue
-			}
-			// look up the entrance pairings
-			mapping, ok := spoiler[matches[1]]
-			if !ok {
-				log.Log.Warn("Found entrance not present in spoiler log",
-					zap.String("line", line),
-				)
-			} else {
-				// look up what region this entrance is a part of
-				region, ok := doorRegions[matches[1]]
-				if !ok {
-					log.Log.Warn("Found door with no associated region",
-						zap.String("line", line),
-					)
-					continue
-				}
-				// look up what region this exit is a part of
-				exitScene, ok := doorRegions[mapping]
-				if !ok {
-					log.Log.Warn("Found destination door with no associated region",
-						zap.String("line", line),
-						zap.String("origin", matches[1]),
-						zap.String("destination", mapping),
-					)
-					continue
-				}
-
-				temp := payload.Scenes[region]
-				temp.Entrances[matches[1]] = Door{exitScene, mapping}
-				temp.Totals.Entrances.Total++
-				payload.Scenes[region] = temp
+	// holy cross code flags
+	for family, section := range pack.Codes {
+		for check, code := range section {
+			key, value := splitFlag(code)
+			if flag, ok := save.Flags[key]; ok && flag == value {
+				payload.Codes[family][check] = true
 			}
 		}
 	}
-	saveReader.Close()
+}
 
-	// look for unfound entrances
-	for scene, doors := range allDoors {
+// countUndiscovered adds every door in DataPack.Doors that was never entered as an unknown entrance
+func countUndiscovered(payload *Save, pack *DataPack, entrances map[string]struct{}) {
+	for scene, doors := range pack.Doors {
 		for _, door := range doors {
 			// skip shops "because they're weird" (thanks tunic randomizer)
 			if door == "Shop" || door == "Shop Portal" {
@@ -306,32 +216,288 @@ func ParseWithSpoiler(recent, saves, spoilerLoc string) error {
 			}
 		}
 	}
+}
+
+// Combine builds the tracker state from a parsed save, filling in checks and door destinations from the
+// spoiler log when one is given. without a spoiler, doors that have been used are listed with an unknown
+// destination. Debug fields that depend on the files themselves (name, hash, modification times) are left
+// for the caller. the tables come from the data pack meant for the randomizer version, named in Debug.DataPack
+func Combine(spoiler *SpoilerLog, save SaveFile) (Save, []Warning) {
+	version := save.RandomizerVersion
+	if spoiler != nil && spoiler.RandomizerVersion != "" {
+		version = spoiler.RandomizerVersion
+	}
+	pack := SelectDataPack(version)
+
+	payload := newSave(pack)
+	warnings := []Warning{}
+
+	applySaveFile(&payload, pack, save, &warnings)
+	payload.Debug.RandomizerVersion = version
+	payload.Debug.DataPack = pack.Version
+	if spoiler == nil {
+		combineWithoutSpoiler(&payload, pack, save, &warnings)
+	} else {
+		combineWithSpoiler(&payload, pack, *spoiler, save, &warnings)
+	}
+	applyProgression(&payload, pack, save)
+	applyHexagons(&payload, spoiler, save)
+	applyLogic(&payload, pack)
+	return payload, warnings
+}
+
+func combineWithSpoiler(payload *Save, pack *DataPack, spoiler SpoilerLog, save SaveFile, warnings *[]Warning) {
+	regions := pack.Regions
+	payload.Debug.SpoilerSeed = spoiler.Seed
+
+	for _, placement := range spoiler.Placements {
+		temp, ok := payload.Scenes[placement.Scene]
+		if !ok {
+			*warnings = append(*warnings, newWarning("Ignoring unknown check location", "location", placement.Scene))
+			continue
+		}
+
+		payload.Totals.Checks.Total++
+		temp.Totals.Checks.Total++
+		temp.Checks[placement.Check] = placement.Found
+		temp.Items[placement.Check] = newItem(placement, save.Archipelago)
+		if !placement.Found {
+			payload.Totals.Checks.Undiscovered++
+			temp.Totals.Checks.Undiscovered++
+		}
+		payload.Scenes[placement.Scene] = temp
+	}
+
+	for _, placement := range spoiler.MajorItems {
+		payload.MajorItems = append(payload.MajorItems, MajorItem{
+			Item:  newItem(placement, save.Archipelago),
+			Scene: placement.Scene,
+			Check: placement.Check,
+		})
+	}
+
+	pairings := map[string]string{}
+	for _, pair := range spoiler.Entrances {
+		payload.Totals.Entrances.Total += 2
+		pairings[pair.From] = pair.To
+		pairings[pair.To] = pair.From
+	}
+
+	entrances := map[string]struct{}{}
+	foundShop := false
+
+	for _, portal := range save.Portals {
+		line := fmt.Sprintf("randomizer entered portal %s|1", portal)
+		entrances[portal] = struct{}{}
+		// don't add mapping here, but remember we found the shop(s)
+		if portal == "Shop" {
+			foundShop = true
+			continue
+		}
+		// look up the entrance pairings
+		mapping, ok := pairings[portal]
+		if !ok {
+			*warnings = append(*warnings, newWarning("Found entrance not present in spoiler log", "line", line))
+			continue
+		}
+		// look up what region this entrance is a part of
+		region, ok := regions[portal]
+		if !ok {
+			*warnings = append(*warnings, newWarning("Found door with no associated region", "line", line))
+			continue
+		}
+		// look up what region this exit is a part of
+		exitScene, ok := regions[mapping]
+		if !ok {
+			*warnings = append(*warnings, newWarning("Found destination door with no associated region",
+				"line", line,
+				"origin", portal,
+				"destination", mapping,
+			))
+			continue
+		}
+
+		temp := payload.Scenes[region]
+		temp.Entrances[portal] = Door{Scene: exitScene, Door: mapping, Source: SourceSpoiler}
+		temp.Totals.Entrances.Total++
+		payload.Scenes[region] = temp
+	}
+
+	// look for unfound entrances
+	countUndiscovered(payload, pack, entrances)
 
 	// populate shops
 	if foundShop {
 		temp := payload.Scenes["Shop"]
-		for i, destination := range shopList {
+		for i, destination := range spoiler.Shops {
 			// get region for door
-			region := doorRegions[destination]
+			region := regions[destination]
 			temp.Totals.Entrances.Total++
-			temp.Entrances[fmt.Sprintf("Shop Portal %d", i+1)] = Door{region, destination}
+			temp.Entrances[fmt.Sprintf("Shop Portal %d", i+1)] = Door{Scene: region, Door: destination, Source: SourceSpoiler}
 		}
 		payload.Scenes["Shop"] = temp
 	}
 
 	if payload.Debug.Seed != payload.Debug.SpoilerSeed {
-		log.Log.Warn("save file seed does not match spoiler seed!",
-			zap.String("save seed", payload.Debug.Seed),
-			zap.String("spoiler seed", payload.Debug.SpoilerSeed),
+		*warnings = append(*warnings, newWarning("save file seed does not match spoiler seed!",
+			"save seed", payload.Debug.Seed,
+			"spoiler seed", payload.Debug.SpoilerSeed,
+		))
+	}
+}
+
+func combineWithoutSpoiler(payload *Save, pack *DataPack, save SaveFile, warnings *[]Warning) {
+	regions := pack.Regions
+	entrances := map[string]struct{}{}
+
+	for _, portal := range save.Portals {
+		entrances[portal] = struct{}{}
+		// shops don't have a fixed door to pair against
+		if portal == "Shop" {
+			continue
+		}
+		region, ok := regions[portal]
+		if !ok {
+			*warnings = append(*warnings, newWarning("Found door with no associated region",
+				"line", fmt.Sprintf("randomizer entered portal %s|1", portal),
+			))
+			continue
+		}
+		// without the spoiler we only know the door was used, not where it leads
+		temp := payload.Scenes[region]
+		temp.Entrances[portal] = Door{Scene: UnknownScene, Source: SourceSave}
+		temp.Totals.Entrances.Total++
+		payload.Scenes[region] = temp
+		payload.Totals.Entrances.Total++
+	}
+
+	// look for unfound entrances
+	countUndiscovered(payload, pack, entrances)
+	payload.Totals.Entrances.Total += payload.Totals.Entrances.Undiscovered
+}
+
+// readSaveFile opens and parses a save from the SAVES directory
+func readSaveFile(recent, saves string) (SaveFile, time.Time, error) {
+	saveReader, err := os.Open(path.Join(saves, recent))
+	if err != nil {
+		log.Log.Error("Failed to open save file",
+			zap.String("save location", saves),
+			zap.String("most recent", recent),
+			zap.Error(err),
+		)
+		return SaveFile{}, time.Time{}, fmt.Errorf("Failed to open most recent save file: %w", err)
+	}
+	defer saveReader.Close()
+
+	saveStat, err := saveReader.Stat()
+	if err != nil {
+		log.Log.Error("Failed to get save file stats",
+			zap.String("most recent", recent),
+			zap.Error(err),
+		)
+		return SaveFile{}, time.Time{}, fmt.Errorf("Failed to stat save file: %w", err)
+	}
+
+	save, err := ParseSaveFile(saveReader)
+	if err != nil {
+		log.Log.Error("Failed to read save file",
+			zap.String("most recent", recent),
+			zap.Error(err),
+		)
+		return SaveFile{}, time.Time{}, err
+	}
+	return save, saveStat.ModTime(), nil
+}
+
+func ParseWithSpoiler(recent, saves, spoilerLoc, mismatchPolicy string) error {
+	// get spoiler.log update time
+	spoilerStat, err := os.Stat(spoilerLoc)
+	if err != nil {
+		log.Log.Error("Failed to get spoiler log stats",
+			zap.String("spoiler location", spoilerLoc),
+			zap.Error(err),
+		)
+		return fmt.Errorf("Failed to stat spoiler log: %w", err)
+	}
+
+	// open spoiler.log
+	spoilerReader, err := os.Open(spoilerLoc)
+	if err != nil {
+		log.Log.Error("Failed to open spoiler log",
+			zap.String("spoiler location", spoilerLoc),
+			zap.Error(err),
+		)
+		return fmt.Errorf("Failed to open spoiler log: %w", err)
+	}
+	spoiler, err := ParseSpoilerLog(spoilerReader)
+	spoilerReader.Close()
+	if err != nil {
+		log.Log.Error("Failed to read spoiler log",
+			zap.String("spoiler location", spoilerLoc),
+			zap.Error(err),
 		)
+		return err
+	}
+
+	save, saveMod, err := readSaveFile(recent, saves)
+	if err != nil {
+		return err
+	}
+
+	payload, warnings := Combine(&spoiler, save)
+	logWarnings(warnings)
+	payload = ApplyMismatch(payload, save, mismatchPolicy)
+	if previous := State.Get().Mismatch; payload.Mismatch.Active != previous.Active {
+		if payload.Mismatch.Active {
+			log.Log.Warn("Save and spoiler log seeds no longer match",
+				zap.String("save seed", payload.Mismatch.SaveSeed),
+				zap.String("spoiler seed", payload.Mismatch.SpoilerSeed),
+				zap.String("policy", payload.Mismatch.Policy),
+			)
+		} else {
+			log.Log.Info("Save and spoiler log seeds match again",
+				zap.String("seed", payload.Mismatch.SaveSeed),
+			)
+		}
 	}
 
+	payload.Debug.Name = recent
+	payload.Debug.SpoilerMod = spoilerStat.ModTime()
+	payload.Debug.SaveMod = saveMod
+	hash := md5.Sum([]byte(recent + spoilerStat.ModTime().String()))
+	payload.Debug.Hash = hex.EncodeToString(hash[:])
+
 	log.Log.Debug("Finished parsing",
 		zap.Int("items", payload.Totals.Checks.Total),
 		zap.Int("entrances", payload.Totals.Entrances.Total),
 		zap.String("hash", payload.Debug.Hash),
 	)
 
-	State = payload
+	State.Set(payload)
+	return nil
+}
+
+// ParseWithoutSpoiler rebuilds as much of the tracker state as possible from the save file alone
+func ParseWithoutSpoiler(recent, saves string) error {
+	save, saveMod, err := readSaveFile(recent, saves)
+	if err != nil {
+		return err
+	}
+
+	payload, warnings := Combine(nil, save)
+	logWarnings(warnings)
+
+	payload.Debug.Name = recent
+	payload.Debug.SaveMod = saveMod
+	hash := md5.Sum([]byte(recent + saveMod.String()))
+	payload.Debug.Hash = hex.EncodeToString(hash[:])
+
+	log.Log.Debug("Finished parsing without spoiler",
+		zap.Int("entrances", payload.Totals.Entrances.Total),
+		zap.Int("undiscovered", payload.Totals.Entrances.Undiscovered),
+		zap.String("hash", payload.Debug.Hash),
+	)
+
+	NoSpoilerState.Set(payload)
 	return nil
 }
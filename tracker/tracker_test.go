@@ -0,0 +1,139 @@
+package tracker
+
+import (
+	"bytes"
+	"encoding/json"
+	"entrance1/log"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// go test ./tracker -run TestGolden -update rewrites the golden files from the current parser output
+var update = flag.Bool("update", false, "rewrite the golden files instead of comparing against them")
+
+const (
+	fixtures       = "fixtures"
+	fixtureSave    = "save.tunic"
+	fixtureSpoiler = "Spoiler.log"
+)
+
+func TestMain(m *testing.M) {
+	// the parser warns about the deliberately broken lines in the fixtures, which is just noise here
+	log.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// clean clears the file-system dependent fields so the output only depends on file contents. it also keeps
+// only the scenes something is known about and the codes that have been solved: the rest is the same for every
+// fixture, is already counted in Totals, and would bury the differences that matter in the golden files
+func clean(save Save) Save {
+	save.Debug.Hash = ""
+	save.Debug.SpoilerMod = time.Time{}
+	save.Debug.SaveMod = time.Time{}
+
+	scenes := map[string]Scene{}
+	for name, scene := range save.Scenes {
+		if scene.Totals.Checks.Total > 0 || scene.Totals.Entrances.Undiscovered < scene.Totals.Entrances.Total {
+			scenes[name] = scene
+		}
+	}
+	save.Scenes = scenes
+	codes := map[string]map[string]bool{}
+	for family, section := range save.Codes {
+		for code, solved := range section {
+			if !solved {
+				continue
+			}
+			if codes[family] == nil {
+				codes[family] = map[string]bool{}
+			}
+			codes[family][code] = true
+		}
+	}
+	save.Codes = codes
+	return save
+}
+
+func withSpoiler(dir string) (interface{}, error) {
+	if err := ParseWithSpoiler(fixtureSave, dir, filepath.Join(dir, fixtureSpoiler), ""); err != nil {
+		return nil, err
+	}
+	return clean(State.Get()), nil
+}
+
+func withoutSpoiler(dir string) (interface{}, error) {
+	if err := ParseWithoutSpoiler(fixtureSave, dir); err != nil {
+		return nil, err
+	}
+	return clean(NoSpoilerState.Get()), nil
+}
+
+func checks(dir string) (interface{}, error) {
+	if err := ParseWithSpoiler(fixtureSave, dir, filepath.Join(dir, fixtureSpoiler), ""); err != nil {
+		return nil, err
+	}
+	return CheckItems(State.Get(), RevealAll)
+}
+
+// TestGolden parses every fixture and compares the results against the fixture's golden files: golden.json
+// for the spoiler state, golden-nospoiler.json for the save alone and golden-checks.json for items
+func TestGolden(t *testing.T) {
+	goldens := []struct {
+		file   string
+		render func(dir string) (interface{}, error)
+	}{
+		{"golden.json", withSpoiler},
+		{"golden-nospoiler.json", withoutSpoiler},
+		{"golden-checks.json", checks},
+	}
+
+	entries, err := os.ReadDir(fixtures)
+	if err != nil {
+		t.Fatalf("could not read fixtures: %v", err)
+	}
+	names := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		dir := filepath.Join(fixtures, name)
+		for _, g := range goldens {
+			t.Run(name+"/"+g.file, func(t *testing.T) {
+				result, err := g.render(dir)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := json.MarshalIndent(result, "", "	")
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, '\n')
+
+				path := filepath.Join(dir, g.file)
+				if *update {
+					if err := os.WriteFile(path, got, 0644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				want, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(bytes.ReplaceAll(want, []byte("\r\n"), []byte("\n")), got) {
+					t.Errorf("output differs from %s, run with -update and review the diff", path)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"entrance1/log"
+	"entrance1/settings"
+	"entrance1/tracker"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// problem is something validate found, errors fail the command and warnings don't
+type problem struct {
+	fatal   bool
+	section string
+	message string
+}
+
+func (p problem) String() string {
+	level := "warning"
+	if p.fatal {
+		level = "error"
+	}
+	return fmt.Sprintf("%s: %s: %s", level, p.section, p.message)
+}
+
+// validateSettings checks that the settings point at something the poller and server can use
+func validateSettings(path string, current settings.Settings) []problem {
+	problems := []problem{}
+	fail := func(message string, args ...interface{}) {
+		problems = append(problems, problem{true, "settings", fmt.Sprintf(message, args...)})
+	}
+	warn := func(message string, args ...interface{}) {
+		problems = append(problems, problem{false, "settings", fmt.Sprintf(message, args...)})
+	}
+
+	if _, _, err := net.SplitHostPort(current.Address); err != nil {
+		fail("address %q is not host:port: %v", current.Address, err)
+	}
+	if current.MismatchPolicy != "" && tracker.MismatchPolicy(current.MismatchPolicy) != current.MismatchPolicy {
+		fail("mismatchPolicy %q is not one of saveonly, withhold or serve", current.MismatchPolicy)
+	}
+	if current.MapLayout != "" {
+		if _, err := tracker.LoadLayout(current.MapLayout); err != nil {
+			fail("mapLayout could not be loaded: %v", err)
+		}
+	}
+
+	if current.SecretLegend == "" {
+		// the default, which looks for SAVES and Randomizer in the working directory
+		warn("secretLegend is not set in %s, using the working directory", path)
+	}
+	saves := filepath.Join(current.SecretLegend, "SAVES")
+	files, err := os.ReadDir(saves)
+	if err != nil {
+		fail("could not read SAVES directory: %v", err)
+		return problems
+	}
+	found := 0
+	pinned := false
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".tunic") {
+			found++
+			pinned = pinned || file.Name() == current.PinnedSave
+		}
+	}
+	if found == 0 {
+		warn("no .tunic files in %s", saves)
+	}
+	if current.PinnedSave != "" && !pinned {
+		warn("pinnedSave %s is not in %s, the most recent save will be tracked", current.PinnedSave, saves)
+	}
+	spoiler := filepath.Join(current.SecretLegend, "Randomizer", "Spoiler.log")
+	if _, err := os.Stat(spoiler); err != nil {
+		warn("no spoiler log, only the spoiler-free state will be available: %v", err)
+	}
+	return problems
+}
+
+func validate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	config := fs.String("config", settings.Path, "settings file to check")
+	address := fs.String("address", "", "address to check instead of the one in the settings file")
+	secretLegend := fs.String("secret-legend", "", "Secret Legend data directory to check instead of the one in the settings file")
+	fs.Parse(args)
+
+	log.InitializeWriter(os.Stderr)
+
+	problems := []problem{}
+	// read the file directly, loading it would create a default one when it's missing
+	current, err := settings.Read(*config)
+	if errors.Is(err, os.ErrNotExist) {
+		problems = append(problems, problem{false, "settings", fmt.Sprintf("%s does not exist, defaults will be used", *config)})
+		current = settings.Defaults
+	} else if err != nil {
+		problems = append(problems, problem{true, "settings", err.Error()})
+	}
+	if *address != "" {
+		current.Address = *address
+	}
+	if *secretLegend != "" {
+		current.SecretLegend = *secretLegend
+	}
+	if err == nil || errors.Is(err, os.ErrNotExist) {
+		problems = append(problems, validateSettings(*config, current)...)
+	}
+	if err := tracker.LoadDataPacks(tracker.PackDir()); err != nil {
+		problems = append(problems, problem{true, "tables", err.Error()})
+	}
+	for _, table := range tracker.ValidateTables() {
+		problems = append(problems, problem{table.Fatal, "tables", table.String()})
+	}
+
+	fatal := false
+	for _, p := range problems {
+		fmt.Println(p)
+		fatal = fatal || p.fatal
+	}
+	if fatal {
+		return 1
+	}
+	fmt.Println("ok")
+	return 0
+}
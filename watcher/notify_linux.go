@@ -0,0 +1,101 @@
+//go:build linux
+
+package watcher
+
+import (
+	"entrance1/log"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/sys/unix"
+)
+
+type inotify struct {
+	mu      sync.Mutex
+	fd      int
+	watches map[string]int
+	changes chan struct{}
+	done    chan struct{}
+}
+
+const watchMask = unix.IN_CREATE | unix.IN_MODIFY | unix.IN_CLOSE_WRITE | unix.IN_MOVED_TO | unix.IN_MOVED_FROM | unix.IN_DELETE
+
+func newNotifier() (notifier, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	n := &inotify{
+		fd:      fd,
+		watches: map[string]int{},
+		changes: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	go n.read()
+	return n, nil
+}
+
+func (n *inotify) sync(dirs []string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	wanted := map[string]bool{}
+	for _, dir := range dirs {
+		wanted[dir] = true
+		if _, ok := n.watches[dir]; ok {
+			continue
+		}
+		// the directory may not exist yet, in which case we try again on the next poll
+		wd, err := unix.InotifyAddWatch(n.fd, dir, watchMask)
+		if err != nil {
+			continue
+		}
+		n.watches[dir] = wd
+	}
+	for dir, wd := range n.watches {
+		if !wanted[dir] {
+			unix.InotifyRmWatch(n.fd, uint32(wd))
+			delete(n.watches, dir)
+		}
+	}
+}
+
+func (n *inotify) events() <-chan struct{} {
+	return n.changes
+}
+
+func (n *inotify) close() {
+	close(n.done)
+}
+
+func (n *inotify) read() {
+	defer unix.Close(n.fd)
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	fds := []unix.PollFd{{Fd: int32(n.fd), Events: unix.POLLIN}}
+	for {
+		select {
+		case <-n.done:
+			return
+		default:
+		}
+		// wake up regularly so close() is noticed
+		count, err := unix.Poll(fds, 500)
+		if err != nil && err != unix.EINTR {
+			log.Log.Error("Failed to poll for change notifications",
+				zap.Error(err),
+			)
+			return
+		}
+		if count <= 0 {
+			continue
+		}
+		// the contents of the events don't matter, only that something changed
+		if _, err := unix.Read(n.fd, buf); err != nil {
+			continue
+		}
+		select {
+		case n.changes <- struct{}{}:
+		default:
+		}
+	}
+}
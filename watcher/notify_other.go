@@ -0,0 +1,11 @@
+//go:build !linux && !windows
+
+package watcher
+
+import (
+	"fmt"
+)
+
+func newNotifier() (notifier, error) {
+	return nil, fmt.Errorf("change notifications are not supported on this platform")
+}
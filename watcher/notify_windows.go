@@ -0,0 +1,159 @@
+//go:build windows
+
+package watcher
+
+import (
+	"entrance1/log"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/sys/windows"
+)
+
+// readDirectoryChanges runs one overlapped ReadDirectoryChangesW per watched directory
+type readDirectoryChanges struct {
+	mu sync.Mutex
+	// watches holds the channel that stops each directory's reader
+	watches map[string]chan struct{}
+	changes chan struct{}
+}
+
+const watchMask = windows.FILE_NOTIFY_CHANGE_FILE_NAME | windows.FILE_NOTIFY_CHANGE_DIR_NAME |
+	windows.FILE_NOTIFY_CHANGE_SIZE | windows.FILE_NOTIFY_CHANGE_LAST_WRITE
+
+func newNotifier() (notifier, error) {
+	return &readDirectoryChanges{
+		watches: map[string]chan struct{}{},
+		changes: make(chan struct{}, 1),
+	}, nil
+}
+
+func (n *readDirectoryChanges) sync(dirs []string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	wanted := map[string]bool{}
+	for _, dir := range dirs {
+		wanted[dir] = true
+		if _, ok := n.watches[dir]; ok {
+			continue
+		}
+		// the directory may not exist yet, in which case we try again on the next poll
+		handle, err := open(dir)
+		if err != nil {
+			continue
+		}
+		stop := make(chan struct{})
+		n.watches[dir] = stop
+		go n.read(dir, handle, stop)
+	}
+	for dir, stop := range n.watches {
+		if !wanted[dir] {
+			close(stop)
+			delete(n.watches, dir)
+		}
+	}
+}
+
+func (n *readDirectoryChanges) events() <-chan struct{} {
+	return n.changes
+}
+
+func (n *readDirectoryChanges) close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for dir, stop := range n.watches {
+		close(stop)
+		delete(n.watches, dir)
+	}
+}
+
+func open(dir string) (windows.Handle, error) {
+	name, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+	return windows.CreateFile(name,
+		windows.FILE_LIST_DIRECTORY,
+		// let the game keep writing, renaming and deleting its saves while we watch
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil,
+		windows.OPEN_EXISTING,
+		windows.FILE_FLAG_BACKUP_SEMANTICS|windows.FILE_FLAG_OVERLAPPED,
+		0,
+	)
+}
+
+// forget drops dir once its reader has given up, so the next sync opens it again
+func (n *readDirectoryChanges) forget(dir string, stop chan struct{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.watches[dir] == stop {
+		delete(n.watches, dir)
+	}
+}
+
+func (n *readDirectoryChanges) read(dir string, handle windows.Handle, stop chan struct{}) {
+	defer windows.CloseHandle(handle)
+	event, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		log.Log.Error("Failed to create change notification event",
+			zap.String("dir", dir),
+			zap.Error(err),
+		)
+		n.forget(dir, stop)
+		return
+	}
+	defer windows.CloseHandle(event)
+
+	// the contents of the events don't matter, only that something changed
+	buf := make([]byte, 64*1024)
+	for {
+		windows.ResetEvent(event)
+		overlapped := windows.Overlapped{HEvent: event}
+		if err := windows.ReadDirectoryChanges(handle, &buf[0], uint32(len(buf)), false, watchMask, nil, &overlapped, 0); err != nil {
+			log.Log.Error("Failed to read change notifications",
+				zap.String("dir", dir),
+				zap.Error(err),
+			)
+			n.forget(dir, stop)
+			return
+		}
+
+		// wake up regularly so a stop is noticed
+		for waiting := true; waiting; {
+			select {
+			case <-stop:
+				windows.CancelIoEx(handle, &overlapped)
+				var done uint32
+				windows.GetOverlappedResult(handle, &overlapped, &done, true)
+				return
+			default:
+			}
+			result, err := windows.WaitForSingleObject(event, 500)
+			if err != nil {
+				log.Log.Error("Failed to wait for change notifications",
+					zap.String("dir", dir),
+					zap.Error(err),
+				)
+				windows.CancelIoEx(handle, &overlapped)
+				var done uint32
+				windows.GetOverlappedResult(handle, &overlapped, &done, true)
+				n.forget(dir, stop)
+				return
+			}
+			waiting = result != windows.WAIT_OBJECT_0
+		}
+
+		var done uint32
+		if err := windows.GetOverlappedResult(handle, &overlapped, &done, false); err != nil {
+			// usually the directory itself went away
+			n.forget(dir, stop)
+			return
+		}
+		select {
+		case n.changes <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package watcher
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"entrance1/log"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type (
+	// notifier is the platform specific change notification backend
+	notifier interface {
+		// sync makes sure exactly the given directories are being watched
+		sync(dirs []string)
+		events() <-chan struct{}
+		close()
+	}
+	// Watcher sends on C once for every settled change to the watched directories
+	Watcher struct {
+		C <-chan struct{}
+
+		out      chan struct{}
+		dirs     func() []string
+		debounce time.Duration
+		poll     time.Duration
+		notify   notifier
+		last     string
+		done     chan struct{}
+	}
+)
+
+const (
+	// how often to poll when change notifications are unavailable
+	FastPoll = 200 * time.Millisecond
+	// how often to double check the directories when change notifications are working
+	SlowPoll = 5 * time.Second
+	// how long a directory must stay quiet before a change is reported, so half-written saves are skipped
+	Debounce = 250 * time.Millisecond
+)
+
+// New starts watching the directories returned by dirs, which is re-evaluated on every poll so that
+// directories can change or appear later. C always receives once on startup
+func New(dirs func() []string, debounce time.Duration) *Watcher {
+	out := make(chan struct{}, 1)
+	w := &Watcher{
+		C:        out,
+		out:      out,
+		dirs:     dirs,
+		debounce: debounce,
+		poll:     SlowPoll,
+		done:     make(chan struct{}),
+	}
+	n, err := newNotifier()
+	if err != nil {
+		log.Log.Warn("Change notifications unavailable, falling back to polling",
+			zap.Error(err),
+		)
+		w.poll = FastPoll
+	} else {
+		w.notify = n
+		n.sync(dirs())
+	}
+	go w.run()
+	return w
+}
+
+// Retry asks for another round after a short delay, for when the files were busy and couldn't be read
+func (w *Watcher) Retry() {
+	time.AfterFunc(FastPoll, w.emit)
+}
+
+func (w *Watcher) Close() {
+	close(w.done)
+}
+
+// fingerprint summarizes the names, sizes and modification times of everything in dirs
+func fingerprint(dirs []string) string {
+	entries := []string{}
+	for _, dir := range dirs {
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			entries = append(entries, dir+"|missing")
+			continue
+		}
+		for _, file := range files {
+			info, err := file.Info()
+			if err != nil {
+				continue
+			}
+			entries = append(entries, fmt.Sprintf("%s|%s|%d|%d", dir, file.Name(), info.Size(), info.ModTime().UnixNano()))
+		}
+	}
+	sort.Strings(entries)
+	hash := md5.New()
+	for _, entry := range entries {
+		hash.Write([]byte(entry + "\n"))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (w *Watcher) emit() {
+	select {
+	case w.out <- struct{}{}:
+	default:
+		// a change is already waiting to be picked up, which covers this one too
+	}
+}
+
+func (w *Watcher) run() {
+	var raw <-chan struct{}
+	if w.notify != nil {
+		raw = w.notify.events()
+		defer w.notify.close()
+	}
+	poll := time.NewTicker(w.poll)
+	defer poll.Stop()
+
+	w.last = fingerprint(w.dirs())
+	w.emit()
+
+	// nil until a change is seen, then fires once the directories have been quiet for the debounce period
+	var settle <-chan time.Time
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-raw:
+			settle = time.After(w.debounce)
+		case <-poll.C:
+			dirs := w.dirs()
+			if w.notify != nil {
+				w.notify.sync(dirs)
+			}
+			if settle == nil && fingerprint(dirs) != w.last {
+				settle = time.After(w.debounce)
+			}
+		case <-settle:
+			settle = nil
+			// notifications fire for every partial write, so only report when something actually changed
+			current := fingerprint(w.dirs())
+			if current == w.last {
+				continue
+			}
+			w.last = current
+			w.emit()
+		}
+	}
+}
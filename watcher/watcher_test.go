@@ -0,0 +1,118 @@
+package watcher
+
+import (
+	"entrance1/log"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const testDebounce = 100 * time.Millisecond
+
+func init() {
+	log.Log = zap.NewNop()
+}
+
+// receive waits up to timeout for a value on C
+func receive(w *Watcher, timeout time.Duration) bool {
+	select {
+	case <-w.C:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func newTestWatcher(t *testing.T, dir string) *Watcher {
+	t.Helper()
+	w := New(func() []string { return []string{dir} }, testDebounce)
+	t.Cleanup(w.Close)
+	if !receive(w, time.Second) {
+		t.Fatal("no value on C at startup")
+	}
+	return w
+}
+
+func TestBurstTriggersOnce(t *testing.T) {
+	dir := t.TempDir()
+	w := newTestWatcher(t, dir)
+
+	// a save being written in pieces, the way the game does it, plus the spoiler log appearing next to it
+	save := filepath.Join(dir, "save.tunic")
+	for i := 0; i < 10; i++ {
+		f, err := os.OpenFile(save, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(f, "line %d\n", i)
+		f.Close()
+		time.Sleep(testDebounce / 10)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Spoiler.log"), []byte("Seed: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// long enough for the fallback poller to notice as well
+	if !receive(w, SlowPoll+time.Second) {
+		t.Fatal("no value on C after writing files")
+	}
+	if receive(w, 3*testDebounce) {
+		t.Fatal("more than one value on C for a single burst of writes")
+	}
+}
+
+func TestQuietDirectoryStaysQuiet(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "save.tunic"), []byte("line\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w := newTestWatcher(t, dir)
+
+	if receive(w, 3*testDebounce) {
+		t.Fatal("value on C without any change")
+	}
+}
+
+// notifications fire on writes that leave the directory exactly as it was, which shouldn't be reported
+func TestUnchangedRewriteIgnored(t *testing.T) {
+	dir := t.TempDir()
+	save := filepath.Join(dir, "save.tunic")
+	if err := os.WriteFile(save, []byte("line\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(save)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newTestWatcher(t, dir)
+
+	if err := os.WriteFile(save, []byte("line\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(save, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if receive(w, 3*testDebounce) {
+		t.Fatal("value on C for a rewrite that changed nothing")
+	}
+}
+
+func TestDirectoryAppearsLater(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "SAVES")
+	w := newTestWatcher(t, dir)
+
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "save.tunic"), []byte("line\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// the directory is only watched from the next poll, which picks the change up itself
+	if !receive(w, SlowPoll+time.Second) {
+		t.Fatal("no value on C after the directory was created")
+	}
+}